@@ -0,0 +1,349 @@
+package vec
+
+import "math"
+
+/*
+Barycentric Lagrange interpolation
+
+Evaluates the polynomial through the data with the
+"second (true) form" of the barycentric formula:
+
+p(x) = sum(w[j]*y[j]/(x-x[j])) / sum(w[j]/(x-x[j]))
+
+which is numerically stable on well-distributed nodes
+(e.g. Chebyshev points) even for hundreds of points.
+
+See:
+J.-P. Berrut & L.N. Trefethen, SIAM Review 46(3), 501-517 (2004)
+*/
+
+/*
+BarycentricInterpolation type def
+points to a BiVariateData container and holds
+the barycentric weight of each node
+*/
+type BarycentricInterpolation struct {
+	data *BiVariateData
+	ws   []float64
+	ext  Extrapolation
+}
+
+/*
+Returns the N Chebyshev points of the second kind on [a, b]
+in ascending order. Unlike Arange, both endpoints are included.
+*/
+func Chebpts(a float64, b float64, N int) []float64 {
+	if notRat(a) || notRat(b) || N <= 0 {
+		return []float64{}
+	}
+	if N == 1 {
+		return []float64{(a + b) / 2.0}
+	}
+	f := make([]float64, N)
+	n := float64(N - 1)
+	for i := range f {
+		//sin() form keeps the points exactly symmetric
+		f[i] = (a+b)/2.0 - (b-a)/2.0*math.Sin(math.Pi*(n-2*float64(i))/(2*n))
+	}
+	f[0], f[N-1] = a, b
+	return f
+}
+
+/*
+Samples 'f' at N Chebyshev points on [a, b] and
+returns the interpolating polynomial
+
+Converges geometrically for analytic 'f'.
+*/
+func ChebInterp(f Mathop, a float64, b float64, N int) *BarycentricInterpolation {
+	xs := Chebpts(a, b, N)
+	ys := Chebpts(a, b, N)
+	PPmap(f, ys)
+
+	//closed-form weights for Chebyshev points
+	ws := make([]float64, N)
+	for i := range ws {
+		ws[i] = 1.0
+		if i%2 == 1 {
+			ws[i] = -1.0
+		}
+	}
+	if N > 1 {
+		ws[0] /= 2.0
+		ws[N-1] /= 2.0
+	}
+	return &BarycentricInterpolation{data: MakeBiVariateData(xs, ys), ws: ws}
+}
+
+/*
+Constructs the polynomial interpolating the data in 'd'
+(degree len(d.Xs)-1)
+
+Weights are computed in logarithmic form, so they neither
+overflow nor underflow for large numbers of nodes.
+Node x-values must be distinct.
+*/
+func Barycentric(d *BiVariateData) *BarycentricInterpolation {
+	d.Sort()
+	N := len(d.Xs)
+	logs := make([]float64, N)
+	ws := make([]float64, N)
+	top := math.Inf(-1)
+	for j := range ws {
+		ws[j] = 1.0
+		for k := range ws {
+			if k == j {
+				continue
+			}
+			diff := d.Xs[j] - d.Xs[k]
+			logs[j] -= math.Log(math.Abs(diff))
+			if diff < 0 {
+				ws[j] = -ws[j]
+			}
+		}
+		top = math.Max(top, logs[j])
+	}
+	for j := range ws {
+		ws[j] *= math.Exp(logs[j] - top)
+	}
+	return &BarycentricInterpolation{data: d, ws: ws}
+}
+
+/*
+Constructs the Floater-Hormann rational interpolation of
+the data in 'd' with blending degree 'deg'
+
+The interpolant blends the polynomials through each run of
+deg+1 consecutive nodes; it has no real poles, reproduces
+polynomials of degree 'deg', and converges at order deg+1 on
+equispaced nodes without Runge oscillation. deg = 3 or 4 is
+a good choice for smooth data; deg = len(d.Xs)-1 recovers
+the polynomial interpolant of Barycentric().
+
+Returns ErrParam if 'deg' is negative. Node x-values must be distinct.
+
+See:
+M.S. Floater & K. Hormann, Numer. Math. 107, 315-331 (2007)
+*/
+func FloaterHormann(d *BiVariateData, deg int) (*BarycentricInterpolation, error) {
+	if deg < 0 {
+		return nil, ErrParam
+	}
+	d.Sort()
+	N := len(d.Xs)
+	if deg > N-1 {
+		deg = N - 1
+	}
+
+	//w[k] = (-1)^(k-deg) * sum over the runs i..i+deg containing k
+	//of prod(1/|x[k]-x[j]|) for j != k in the run
+	ws := make([]float64, N)
+	top := 0.0
+	for k := range ws {
+		lo, hi := k-deg, k
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > N-1-deg {
+			hi = N - 1 - deg
+		}
+		for i := lo; i <= hi; i++ {
+			prod := 1.0
+			for j := i; j <= i+deg; j++ {
+				if j != k {
+					prod /= math.Abs(d.Xs[k] - d.Xs[j])
+				}
+			}
+			ws[k] += prod
+		}
+		if (k-deg)%2 != 0 {
+			ws[k] = -ws[k]
+		}
+		top = math.Max(top, math.Abs(ws[k]))
+	}
+	for k := range ws {
+		ws[k] /= top
+	}
+	return &BarycentricInterpolation{data: d, ws: ws}, nil
+}
+
+/*
+Returns a copy of 'p' that uses the policy 'ext' outside
+the data endpoints; 'p' itself is unchanged
+*/
+func (p *BarycentricInterpolation) Extrapolate(ext Extrapolation) *BarycentricInterpolation {
+	out := *p
+	out.ext = ext
+	return &out
+}
+
+//Returns the first and last nodes
+func (p *BarycentricInterpolation) Domain() (float64, float64) {
+	return p.data.Xs[0], p.data.Xs[len(p.data.Xs)-1]
+}
+
+//index of the node equal to 'x', or -1
+func (p *BarycentricInterpolation) node(x float64) int {
+	for j, xj := range p.data.Xs {
+		if x == xj {
+			return j
+		}
+	}
+	return -1
+}
+
+//Returns the interpolated value at 'x'
+func (p *BarycentricInterpolation) F(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(p, p.ext, x, 0); ok {
+		return out
+	}
+	if j := p.node(x); j >= 0 {
+		return p.data.Ys[j]
+	}
+	num, den := 0.0, 0.0
+	for j, xj := range p.data.Xs {
+		c := p.ws[j] / (x - xj)
+		num += c * p.data.Ys[j]
+		den += c
+	}
+	return num / den
+}
+
+/*
+First derivative at 'x'
+
+Away from the nodes, differentiates the identity
+sum(w[j]*(p(x)-y[j])/(x-x[j])) = 0; at a node, uses
+the corresponding row of the differentiation matrix.
+*/
+func (p *BarycentricInterpolation) DF(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(p, p.ext, x, 1); ok {
+		return out
+	}
+	d1, _ := p.derivs(x)
+	return d1
+}
+
+//Second derivative at 'x'
+func (p *BarycentricInterpolation) DDF(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(p, p.ext, x, 2); ok {
+		return out
+	}
+	_, d2 := p.derivs(x)
+	return d2
+}
+
+//first and second derivatives at 'x'
+func (p *BarycentricInterpolation) derivs(x float64) (d1 float64, d2 float64) {
+	xs, ys, ws := p.data.Xs, p.data.Ys, p.ws
+
+	if i := p.node(x); i >= 0 {
+		//D[i][j] = (w[j]/w[i])/(x[i]-x[j]), D[i][i] = -sum(D[i][j])
+		Dii := 0.0
+		for j := range xs {
+			if j != i {
+				Dii -= (ws[j] / ws[i]) / (xs[i] - xs[j])
+			}
+		}
+		for j := range xs {
+			if j == i {
+				continue
+			}
+			Dij := (ws[j] / ws[i]) / (xs[i] - xs[j])
+			d1 += Dij * (ys[j] - ys[i])
+			d2 += 2 * Dij * (Dii - 1.0/(xs[i]-xs[j])) * (ys[j] - ys[i])
+		}
+		return
+	}
+
+	px := p.F(x)
+	den := 0.0
+	for j := range xs {
+		c := ws[j] / (x - xs[j])
+		den += c
+		d1 += c * (px - ys[j]) / (x - xs[j])
+	}
+	d1 /= den
+	for j := range xs {
+		r := (px - ys[j]) / (x - xs[j])
+		d2 += 2 * ws[j] * (d1 - r) / ((x - xs[j]) * (x - xs[j]))
+	}
+	d2 /= den
+	return
+}
+
+/*
+Returns the definite integral from 'a' to 'b'
+
+Integrates with 10-point Gauss-Legendre quadrature
+between each pair of nodes (exact for polynomials of
+degree < 20, and accurate to rounding for smooth data
+on Chebyshev points).
+*/
+func (p *BarycentricInterpolation) Integral(a float64, b float64) float64 {
+	if notRat(a) || notRat(b) {
+		return math.NaN()
+	}
+	if a > b {
+		return -p.Integral(b, a)
+	}
+	if out, ok := extrapIntegral(p, p.ext, a, b); ok {
+		return out
+	}
+	return segSum(p.data, a, b, func(i int, x1 float64, x2 float64) float64 {
+		return gaussLegendre(p.F, x1, x2)
+	})
+}
+
+//10-point Gauss-Legendre nodes and weights on [-1, 1]
+var glNodes, glWeights = legendreRule(10)
+
+//Gauss-Legendre quadrature of 'f' from 'a' to 'b'
+func gaussLegendre(f Mathop, a float64, b float64) float64 {
+	mid, half := (a+b)/2.0, (b-a)/2.0
+	out := 0.0
+	for i, t := range glNodes {
+		out += glWeights[i] * f(mid+half*t)
+	}
+	return out * half
+}
+
+/*
+Computes the nodes and weights of the N-point
+Gauss-Legendre rule by Newton iteration on the
+Legendre polynomial P_N
+*/
+func legendreRule(N int) (xs []float64, ws []float64) {
+	xs = make([]float64, N)
+	ws = make([]float64, N)
+	for i := 0; i < (N+1)/2; i++ {
+		//initial guess from the asymptotic formula
+		x := math.Cos(math.Pi * (float64(i) + 0.75) / (float64(N) + 0.5))
+		var dp float64
+		for iter := 0; iter < 100; iter++ {
+			p0, p1 := 1.0, x
+			for k := 2; k <= N; k++ {
+				p0, p1 = p1, ((2*float64(k)-1)*x*p1-(float64(k)-1)*p0)/float64(k)
+			}
+			dp = float64(N) * (x*p1 - p0) / (x*x - 1)
+			dx := p1 / dp
+			x -= dx
+			if math.Abs(dx) < 1E-16 {
+				break
+			}
+		}
+		xs[i], xs[N-1-i] = -x, x
+		ws[i] = 2.0 / ((1 - x*x) * dp * dp)
+		ws[N-1-i] = ws[i]
+	}
+	return
+}
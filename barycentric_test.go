@@ -0,0 +1,115 @@
+package vec
+
+import "testing"
+import "math"
+
+func runge(x float64) float64 {
+	return 1.0 / (1.0 + 25*x*x)
+}
+
+/*
+Test Chebyshev interpolation of Runge's function
+with hundreds of nodes
+
+- values, derivatives and integral to near rounding
+*/
+func TestChebInterp(t *testing.T) {
+	p := ChebInterp(runge, -1, 1, 301)
+	for _, x := range []float64{-0.999, -0.3127, 0.0, 0.01, 0.5, 0.98} {
+		if math.Abs(p.F(x)-runge(x)) > 1E-13 {
+			t.Error("ChebInterp inaccurate at", x, "Got:", p.F(x), "Expected:", runge(x))
+		}
+		df := -50 * x * runge(x) * runge(x)
+		if math.Abs(p.DF(x)-df) > 1E-9 {
+			t.Error("ChebInterp derivative inaccurate at", x, "Got:", p.DF(x), "Expected:", df)
+		}
+	}
+
+	//derivatives at the nodes use the differentiation matrix
+	x := p.data.Xs[150]
+	if math.Abs(p.DF(x)) > 1E-9 || math.Abs(p.DDF(x)+50.0) > 1E-6 {
+		t.Error("ChebInterp node derivatives inaccurate. Got:", p.DF(x), p.DDF(x))
+	}
+	if math.Abs(p.DDF(0.3)-runge(0.3)*runge(0.3)*runge(0.3)*(5000*0.09-50*(1+25*0.09))) > 1E-6 {
+		t.Error("ChebInterp second derivative inaccurate. Got:", p.DDF(0.3))
+	}
+
+	if relativeError(p.Integral(-1, 1), 0.4*math.Atan(5.0)) > 1E-13 {
+		t.Error("ChebInterp integral inaccurate. Got:", p.Integral(-1, 1), "Expected:", 0.4*math.Atan(5.0))
+	}
+}
+
+//Barycentric interpolation on arbitrary nodes reproduces a polynomial
+func TestBarycentric(t *testing.T) {
+	poly := func(x float64) float64 {
+		return 2*x*x*x*x - x*x + 3
+	}
+	xs := []float64{0.3, -1, 2, 1.1, 0}
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = poly(x)
+	}
+	p := Barycentric(MakeBiVariateData(xs, ys))
+	for _, x := range []float64{-0.5, 0.7, 1.9, 3.0} {
+		if !areSimilar(p.F(x), poly(x)) {
+			t.Error("Barycentric does not reproduce a quartic at", x, "Got:", p.F(x), "Expected:", poly(x))
+		}
+		if math.Abs(p.DF(x)-(8*x*x*x-2*x)) > 1E-10 {
+			t.Error("Barycentric derivative is wrong at", x, "Got:", p.DF(x))
+		}
+	}
+	if relativeError(p.Integral(-1, 2), 2*(32.0+1)/5-(8.0+1)/3+9) > 1E-13 {
+		t.Error("Barycentric integral is wrong. Got:", p.Integral(-1, 2))
+	}
+}
+
+/*
+Test Floater-Hormann interpolation of Runge's function
+on an equispaced grid
+
+- no Runge oscillation, convergence with more nodes
+- polynomials up to the blending degree are reproduced
+- full degree recovers the polynomial interpolant
+*/
+func TestFloaterHormann(t *testing.T) {
+	maxErr := func(N int) float64 {
+		xs := Arange(-1, 1.0+2.0/float64(N-1), N)
+		ys := Arange(-1, 1.0+2.0/float64(N-1), N)
+		PPmap(runge, ys)
+		p, _ := FloaterHormann(MakeBiVariateData(xs, ys), 3)
+		e := 0.0
+		for _, x := range Arange(-1, 1, 397) {
+			e = math.Max(e, math.Abs(p.F(x)-runge(x)))
+		}
+		return e
+	}
+	e1, e2 := maxErr(41), maxErr(161)
+	if e1 > 1E-2 || e2 > 1E-5 || e2 > e1/50 {
+		t.Error("FloaterHormann not converging on Runge's function. Errors:", e1, e2)
+	}
+
+	cubic := func(x float64) float64 { return 2*x*x*x - x + 3 }
+	xs := []float64{0, 0.3, 0.5, 1.1, 1.2, 2, 2.6}
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = cubic(x)
+	}
+	p, _ := FloaterHormann(MakeBiVariateData(xs, ys), 3)
+	for _, x := range []float64{0.1, 0.77, 1.5, 2.5} {
+		if math.Abs(p.F(x)-cubic(x)) > 1E-12 || math.Abs(p.DF(x)-(6*x*x-1)) > 1E-10 {
+			t.Error("FloaterHormann did not reproduce a cubic at", x, "Got:", p.F(x), p.DF(x))
+		}
+	}
+	if math.Abs(p.Integral(0, 2.6)-(0.5*math.Pow(2.6, 4)-0.5*2.6*2.6+3*2.6)) > 1E-10 {
+		t.Error("FloaterHormann integral inaccurate. Got:", p.Integral(0, 2.6))
+	}
+
+	q, _ := FloaterHormann(MakeBiVariateData(xs, ys), 10)
+	b := Barycentric(MakeBiVariateData(xs, ys))
+	if math.Abs(q.F(0.9)-b.F(0.9)) > 1E-12 {
+		t.Error("FloaterHormann of full degree differs from Barycentric.")
+	}
+	if _, err := FloaterHormann(MakeBiVariateData(xs, ys), -1); err != ErrParam {
+		t.Error("Expected ErrParam for a negative blending degree. Got:", err)
+	}
+}
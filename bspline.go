@@ -0,0 +1,273 @@
+package vec
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+/*
+B-splines
+
+A BSpline of degree k is sum(Coeffs[i]*B[i,k](x)), where the
+basis functions B[i,k] are defined by the non-decreasing knot
+vector (len(Knots) = len(Coeffs) + k + 1). The spline is defined
+on [Knots[k], Knots[len(Coeffs)]]; outside that range the end
+polynomial pieces are continued.
+
+All fields are exported so fitted models can be stored and
+rebuilt with a struct literal.
+
+See:
+C. de Boor, "A Practical Guide to Splines" (1978)
+*/
+
+//BSpline type def
+type BSpline struct {
+	Knots  []float64
+	Coeffs []float64
+	Degree int
+}
+
+//ErrKnots - a knot vector that cannot define a spline
+var ErrKnots = errors.New("vec: invalid knot vector")
+
+/*
+Returns a clamped knot vector on [a, b] (each end repeated
+degree+1 times) with the given interior knots, which must
+lie strictly inside (a, b) in ascending order
+*/
+func ClampedKnots(a float64, b float64, interior []float64, degree int) []float64 {
+	out := make([]float64, 0, len(interior)+2*(degree+1))
+	for i := 0; i <= degree; i++ {
+		out = append(out, a)
+	}
+	out = append(out, interior...)
+	for i := 0; i <= degree; i++ {
+		out = append(out, b)
+	}
+	return out
+}
+
+/*
+Automatic knot placement: a clamped knot vector over the range
+of 'xs' with 'interior' knots at evenly-spaced quantiles of
+'xs', so each knot interval holds about the same number of points
+*/
+func QuantileKnots(xs []float64, interior int, degree int) []float64 {
+	s := append([]float64(nil), xs...)
+	sort.Float64s(s)
+	n := len(s)
+	in := make([]float64, 0, interior)
+	for j := 1; j <= interior; j++ {
+		//linear interpolation between order statistics
+		p := float64(j) / float64(interior+1) * float64(n-1)
+		i := int(p)
+		f := p - float64(i)
+		v := s[i]
+		if i+1 < n {
+			v += f * (s[i+1] - s[i])
+		}
+		in = append(in, v)
+	}
+	return ClampedKnots(s[0], s[n-1], in, degree)
+}
+
+/*
+Least-squares fit of a BSpline with the given knots and
+degree to the data in 'd', with optional weights 'w'
+(nil for equal weights)
+
+Solves the normal equations of the weighted problem.
+Returns ErrLength if 'w' doesn't match 'd', ErrKnots if
+the knot vector is malformed, and ErrSingular if some
+basis function has no data under it.
+*/
+func FitBSpline(d *BiVariateData, w []float64, knots []float64, degree int) (*BSpline, error) {
+	m := len(d.Xs)
+	if len(d.Ys) != m || (w != nil && len(w) != m) {
+		return nil, ErrLength
+	}
+	n := len(knots) - degree - 1
+	if degree < 0 || n < degree+1 || !sort.Float64sAreSorted(knots) || knots[degree] == knots[n] {
+		return nil, ErrKnots
+	}
+
+	b := &BSpline{Knots: knots, Coeffs: make([]float64, n), Degree: degree}
+	A := zeros(n, n)
+	rhs := make([]float64, n)
+	for p, x := range d.Xs {
+		wp := 1.0
+		if w != nil {
+			wp = w[p]
+		}
+		l, N := b.basis(x)
+		for i, Ni := range N {
+			rhs[l-degree+i] += wp * Ni * d.Ys[p]
+			for j, Nj := range N {
+				A[l-degree+i][l-degree+j] += wp * Ni * Nj
+			}
+		}
+	}
+	c, err := solveDense(A, rhs)
+	if err != nil {
+		return nil, err
+	}
+	b.Coeffs = c
+	return b, nil
+}
+
+/*
+Index 'l' of the knot span containing 'x'
+(Knots[l] <= x < Knots[l+1], clamped to the base interval)
+*/
+func (b *BSpline) span(x float64) int {
+	k := b.Degree
+	return k + bracket(b.Knots[k:len(b.Coeffs)+1], x)
+}
+
+/*
+Returns the span 'l' containing 'x' and the values of
+the k+1 basis functions B[l-k] ... B[l] that are nonzero there
+*/
+func (b *BSpline) basis(x float64) (int, []float64) {
+	k, t := b.Degree, b.Knots
+	l := b.span(x)
+	N := make([]float64, k+1)
+	left := make([]float64, k+1)
+	right := make([]float64, k+1)
+	N[0] = 1.0
+	for j := 1; j <= k; j++ {
+		left[j] = x - t[l+1-j]
+		right[j] = t[l+j] - x
+		saved := 0.0
+		for r := 0; r < j; r++ {
+			tmp := N[r] / (right[r+1] + left[j-r])
+			N[r] = saved + right[r+1]*tmp
+			saved = left[j-r] * tmp
+		}
+		N[j] = saved
+	}
+	return l, N
+}
+
+/*
+'nu'-th derivative at 'x' by de Boor's algorithm,
+differencing the local coefficients 'nu' times first
+*/
+func (b *BSpline) eval(x float64, nu int) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	k, t := b.Degree, b.Knots
+	if nu > k {
+		return 0.0
+	}
+	l := b.span(x)
+	d := make([]float64, k+1)
+	copy(d, b.Coeffs[l-k:l+1])
+
+	//local coefficients of the derivative splines
+	for p := k; p > k-nu; p-- {
+		for j := 0; j < p; j++ {
+			den := t[l+j+1] - t[l-p+j+1]
+			if den == 0 {
+				d[j] = 0.0
+			} else {
+				d[j] = float64(p) * (d[j+1] - d[j]) / den
+			}
+		}
+	}
+
+	//de Boor on the remaining degree
+	q := k - nu
+	for r := 1; r <= q; r++ {
+		for j := q; j >= r; j-- {
+			den := t[j+1+l-r] - t[j+l-q]
+			alpha := 0.0
+			if den != 0 {
+				alpha = (x - t[j+l-q]) / den
+			}
+			d[j] = (1-alpha)*d[j-1] + alpha*d[j]
+		}
+	}
+	return d[q]
+}
+
+//Returns the base interval [Knots[k], Knots[n]]
+func (b *BSpline) Domain() (float64, float64) {
+	return b.Knots[b.Degree], b.Knots[len(b.Coeffs)]
+}
+
+//Returns the value of the spline at 'x'
+func (b *BSpline) F(x float64) float64 {
+	return b.eval(x, 0)
+}
+
+//First derivative at 'x'
+func (b *BSpline) DF(x float64) float64 {
+	return b.eval(x, 1)
+}
+
+//Second derivative at 'x'
+func (b *BSpline) DDF(x float64) float64 {
+	return b.eval(x, 2)
+}
+
+/*
+Returns the derivative as a BSpline of degree k-1
+(a zero spline if the degree is already zero)
+*/
+func (b *BSpline) Deriv() *BSpline {
+	k, t, c := b.Degree, b.Knots, b.Coeffs
+	n := len(c)
+	if k == 0 {
+		return &BSpline{Knots: t, Coeffs: make([]float64, n), Degree: 0}
+	}
+	dc := make([]float64, n-1)
+	for i := range dc {
+		den := t[i+k+1] - t[i+1]
+		if den != 0 {
+			dc[i] = float64(k) * (c[i+1] - c[i]) / den
+		}
+	}
+	return &BSpline{Knots: t[1 : len(t)-1], Coeffs: dc, Degree: k - 1}
+}
+
+/*
+Returns the antiderivative as a BSpline of degree k+1,
+equal to zero at the left end of the base interval
+*/
+func (b *BSpline) Antideriv() *BSpline {
+	k, t, c := b.Degree, b.Knots, b.Coeffs
+	n := len(c)
+	ac := make([]float64, n+1)
+	for i := 0; i < n; i++ {
+		ac[i+1] = ac[i] + c[i]*(t[i+k+1]-t[i])/float64(k+1)
+	}
+	at := make([]float64, 0, len(t)+2)
+	at = append(at, t[0])
+	at = append(at, t...)
+	at = append(at, t[len(t)-1])
+	out := &BSpline{Knots: at, Coeffs: ac, Degree: k + 1}
+
+	//shift so the antiderivative vanishes at the start of the base interval
+	if c0 := out.F(t[k]); c0 != 0 {
+		for i := range ac {
+			ac[i] -= c0
+		}
+	}
+	return out
+}
+
+/*
+Returns the definite integral from 'a' to 'b'
+(through the antiderivative spline)
+*/
+func (b *BSpline) Integral(a float64, bb float64) float64 {
+	if notRat(a) || notRat(bb) {
+		return math.NaN()
+	}
+	anti := b.Antideriv()
+	return anti.F(bb) - anti.F(a)
+}
@@ -0,0 +1,68 @@
+package vec
+
+import "testing"
+import "math"
+
+/*
+Test B-spline fitting and evaluation
+
+- a least-squares cubic fit reproduces a cubic exactly
+- derivative and antiderivative splines match DF and Integral
+- a fit with few knots tracks noisy-free smooth data
+*/
+func TestBSpline(t *testing.T) {
+	cub := func(x float64) float64 {
+		return 0.5*x*x*x - x*x + 2
+	}
+	xs := Arange(0, 4, 80)
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = cub(x)
+	}
+	bvd := MakeBiVariateData(xs, ys)
+
+	b, err := FitBSpline(bvd, nil, QuantileKnots(xs, 5, 3), 3)
+	if err != nil {
+		t.Fatal("FitBSpline failed:", err)
+	}
+	if len(b.Coeffs) != 9 || len(b.Knots) != 13 {
+		t.Error("FitBSpline has the wrong shape:", len(b.Coeffs), len(b.Knots))
+	}
+	for _, x := range []float64{0.1, 1.37, 2.5, 3.9} {
+		if math.Abs(b.F(x)-cub(x)) > 1E-10 {
+			t.Error("BSpline does not reproduce a cubic at", x, "Got:", b.F(x), "Expected:", cub(x))
+		}
+		if math.Abs(b.DF(x)-(1.5*x*x-2*x)) > 1E-9 || math.Abs(b.Deriv().F(x)-b.DF(x)) > 1E-12 {
+			t.Error("BSpline derivative is wrong at", x, "Got:", b.DF(x))
+		}
+		if math.Abs(b.DDF(x)-(3*x-2)) > 1E-8 {
+			t.Error("BSpline second derivative is wrong at", x, "Got:", b.DDF(x))
+		}
+	}
+	lo, _ := b.Domain()
+	exact := func(x float64) float64 {
+		return 0.125*x*x*x*x - x*x*x/3 + 2*x
+	}
+	if math.Abs(b.Integral(0.5, 3.5)-(exact(3.5)-exact(0.5))) > 1E-9 {
+		t.Error("BSpline integral is wrong. Got:", b.Integral(0.5, 3.5))
+	}
+	if math.Abs(b.Antideriv().F(lo)) > 1E-15 {
+		t.Error("BSpline antiderivative is not zero at the left end")
+	}
+
+	//weights: a wild point with zero weight is ignored
+	w := make([]float64, len(xs))
+	for i := range w {
+		w[i] = 1.0
+	}
+	ys[40] += 100
+	w[40] = 0
+	b, _ = FitBSpline(MakeBiVariateData(xs, ys), w, ClampedKnots(0, 4, []float64{1, 2, 3}, 3), 3)
+	if math.Abs(b.F(2.0)-cub(2.0)) > 1E-9 {
+		t.Error("FitBSpline did not honour the weights. Got:", b.F(2.0))
+	}
+
+	if _, err := FitBSpline(bvd, nil, ClampedKnots(0, 4, []float64{1.001, 1.002, 1.003, 1.004, 1.005, 1.006}, 3), 3); err != ErrSingular {
+		t.Error("FitBSpline accepted knots with empty intervals. Got:", err)
+	}
+}
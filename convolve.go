@@ -0,0 +1,213 @@
+package vec
+
+import (
+	"math"
+	"runtime"
+	"sync"
+)
+
+/*
+Discrete convolution and correlation of sampled data
+
+Short kernels are convolved directly, splitting the output
+across NumCPU() goroutines as in PPmap; once both inputs
+are longer than convFFTMin the convolution is computed by
+zero-padded radix-2 FFTs in O((N+M) log(N+M)).
+*/
+
+//ConvMode - which part of the full convolution to return
+type ConvMode int
+
+const (
+	//ConvFull - every overlap: length N+M-1
+	ConvFull ConvMode = iota
+	//ConvSame - centred on the longer input: length max(N, M)
+	ConvSame
+	//ConvValid - complete overlaps only: length max(N, M)-min(N, M)+1
+	ConvValid
+)
+
+//shorter input length above which the FFT is used
+const convFFTMin = 64
+
+//direct convolutions with fewer multiplications than this run on one goroutine
+const convParallelMin = 1 << 15
+
+/*
+Returns the discrete convolution of 'dat' with 'conv':
+
+out[k] = sum(dat[j]*conv[k-j])
+
+with the part of the output selected by 'mode'.
+Returns an empty slice if either input is empty.
+*/
+func Convolve(dat []float64, conv []float64, mode ConvMode) []float64 {
+	N, M := len(dat), len(conv)
+	if N == 0 || M == 0 {
+		return []float64{}
+	}
+
+	var full []float64
+	if N >= convFFTMin && M >= convFFTMin {
+		full = fftConvolve(dat, conv)
+	} else {
+		full = directConvolve(dat, conv)
+	}
+
+	short, long := N, M
+	if short > long {
+		short, long = long, short
+	}
+	switch mode {
+	case ConvSame:
+		lo := (short - 1) / 2
+		return full[lo : lo+long]
+	case ConvValid:
+		return full[short-1 : long]
+	}
+	return full
+}
+
+/*
+Returns the discrete cross-correlation of 'dat' with 'v':
+
+out[k] = sum(dat[j+k]*v[j])
+
+ordered from the most negative lag, with the part of the
+output selected by 'mode' (as numpy.correlate).
+*/
+func Correlate(dat []float64, v []float64, mode ConvMode) []float64 {
+	rev := make([]float64, len(v))
+	for i := range v {
+		rev[len(v)-1-i] = v[i]
+	}
+	return Convolve(dat, rev, mode)
+}
+
+/*
+Returns the autocorrelation of 'dat' at lags 0 to len(dat)-1:
+
+out[k] = sum(dat[j]*dat[j+k])
+
+(the negative lags are the same by symmetry)
+*/
+func Autocorrelate(dat []float64) []float64 {
+	if len(dat) == 0 {
+		return []float64{}
+	}
+	return Correlate(dat, dat, ConvFull)[len(dat)-1:]
+}
+
+//full convolution by direct summation
+func directConvolve(dat []float64, conv []float64) []float64 {
+	N, M := len(dat), len(conv)
+	out := make([]float64, N+M-1)
+
+	//output elements 's' to 'e'
+	each := func(s int, e int) {
+		for k := s; k < e; k++ {
+			lo, hi := k-M+1, k
+			if lo < 0 {
+				lo = 0
+			}
+			if hi > N-1 {
+				hi = N - 1
+			}
+			sum := 0.0
+			for j := lo; j <= hi; j++ {
+				sum += dat[j] * conv[k-j]
+			}
+			out[k] = sum
+		}
+	}
+
+	NTHREADS := runtime.NumCPU()
+	l := len(out)
+	batch_size := l / NTHREADS
+	if NTHREADS <= 1 || batch_size == 0 || N*M < convParallelMin {
+		each(0, l)
+		return out
+	}
+	wg := new(sync.WaitGroup)
+	wg.Add(NTHREADS)
+	for i := 0; i < NTHREADS; i++ {
+		start, end := i*batch_size, (i+1)*batch_size
+		if i == NTHREADS-1 {
+			end = l
+		}
+		go func(s int, e int) {
+			each(s, e)
+			wg.Done()
+		}(start, end)
+	}
+	wg.Wait()
+	return out
+}
+
+//full convolution by zero-padded FFTs
+func fftConvolve(dat []float64, conv []float64) []float64 {
+	l := len(dat) + len(conv) - 1
+	n := 1
+	for n < l {
+		n <<= 1
+	}
+	a := make([]complex128, n)
+	b := make([]complex128, n)
+	for i, x := range dat {
+		a[i] = complex(x, 0)
+	}
+	for i, x := range conv {
+		b[i] = complex(x, 0)
+	}
+	fft(a, false)
+	fft(b, false)
+	for i := range a {
+		a[i] *= b[i]
+	}
+	fft(a, true)
+
+	out := make([]float64, l)
+	for i := range out {
+		out[i] = real(a[i]) / float64(n)
+	}
+	return out
+}
+
+/*
+In-place iterative radix-2 FFT of 'a' (len(a) a power of two)
+
+'inverse' flips the sign of the exponent; the inverse is
+not scaled by 1/len(a).
+*/
+func fft(a []complex128, inverse bool) {
+	n := len(a)
+
+	//bit-reversal permutation
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+
+	sgn := -1.0
+	if inverse {
+		sgn = 1.0
+	}
+	for size := 2; size <= n; size <<= 1 {
+		half := size >> 1
+		for k := 0; k < half; k++ {
+			//twiddles computed directly to avoid accumulated rounding
+			th := sgn * 2 * math.Pi * float64(k) / float64(size)
+			w := complex(math.Cos(th), math.Sin(th))
+			for s := 0; s < n; s += size {
+				u, v := a[s+k], w*a[s+k+half]
+				a[s+k], a[s+k+half] = u+v, u-v
+			}
+		}
+	}
+}
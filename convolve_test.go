@@ -0,0 +1,83 @@
+package vec
+
+import "testing"
+import "math"
+
+//direct O(n*m) convolution of 'a' and 'b'
+func naiveConvolve(a []float64, b []float64) []float64 {
+	out := make([]float64, len(a)+len(b)-1)
+	for i := range a {
+		for j := range b {
+			out[i+j] += a[i] * b[j]
+		}
+	}
+	return out
+}
+
+/*
+Test Convolve in each mode
+
+- full, same and valid outputs of a short kernel
+- the parallel direct and FFT paths match the naive sum
+
+EDGE CASES:
+- empty data gives an empty convolution
+*/
+func TestConvolve(t *testing.T) {
+	out := Convolve([]float64{1, 2, 3}, []float64{0, 1, 0.5}, ConvFull)
+	want := []float64{0, 1, 2.5, 4, 1.5}
+	for i := range want {
+		if out[i] != want[i] {
+			t.Error("Convolve full is wrong. Got:", out, "Expected:", want)
+			break
+		}
+	}
+	same := Convolve([]float64{1, 2, 3}, []float64{0, 1, 0.5}, ConvSame)
+	valid := Convolve([]float64{1, 2, 3}, []float64{0, 1, 0.5}, ConvValid)
+	if len(same) != 3 || same[0] != 1 || same[2] != 4 || len(valid) != 1 || valid[0] != 2.5 {
+		t.Error("Convolve same/valid is wrong. Got:", same, valid, "Expected: [1 2.5 4] [2.5]")
+	}
+	if len(Convolve(nil, []float64{1}, ConvFull)) != 0 {
+		t.Error("Expected an empty convolution of empty data.")
+	}
+
+	//direct (parallel) and FFT paths against the naive sum
+	for _, nm := range [][2]int{{5000, 20}, {300, 700}, {1000, 1000}} {
+		a, b := make([]float64, nm[0]), make([]float64, nm[1])
+		for i := range a {
+			a[i] = math.Sin(float64(i) * 0.37)
+		}
+		for i := range b {
+			b[i] = math.Cos(float64(i)*1.3) / float64(i+1)
+		}
+		want := naiveConvolve(a, b)
+		got := Convolve(a, b, ConvFull)
+		for i := range want {
+			if math.Abs(got[i]-want[i]) > 1E-10 {
+				t.Error("Convolve", nm, "is wrong at", i, "Got:", got[i], "Expected:", want[i])
+				break
+			}
+		}
+	}
+}
+
+/*
+Test Correlate and Autocorrelate
+
+- cross-correlation reverses the second argument
+- autocorrelation returns the non-negative lags
+*/
+func TestCorrelate(t *testing.T) {
+	out := Correlate([]float64{1, 2, 3}, []float64{0, 1, 0.5}, ConvFull)
+	want := []float64{0.5, 2, 3.5, 3, 0}
+	for i := range want {
+		if math.Abs(out[i]-want[i]) > 1E-15 {
+			t.Error("Correlate is wrong. Got:", out, "Expected:", want)
+			break
+		}
+	}
+	ac := Autocorrelate([]float64{1, 2, 3})
+	if len(ac) != 3 || ac[0] != 14 || ac[1] != 8 || ac[2] != 3 {
+		t.Error("Autocorrelate is wrong. Got:", ac, "Expected: [14 8 3]")
+	}
+}
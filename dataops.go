@@ -1,15 +1,26 @@
 package vec
 
-import "sort"
+import (
+	"errors"
+	"math"
+	"sort"
+)
 
 /*
 Discreet Data Operations
 */
 
-//Struct for bivariate data (xs, ys)
+/*
+Struct for bivariate data (xs, ys)
+
+'Errs' optionally holds the standard error of each y-value
+(nil if unknown); when set, it is kept in step with the
+points by Sort and Append.
+*/
 type BiVariateData struct {
 	Xs       []float64
 	Ys       []float64
+	Errs     []float64
 	isSorted bool
 }
 
@@ -23,11 +34,167 @@ NOTE: sorting is done on slices, which means that 'xs' and 'ys' may be changed
 by calling this function
 */
 func MakeBiVariateData(xs []float64, ys []float64) *BiVariateData {
-	out := BiVariateData{xs, ys, false}
+	out := BiVariateData{Xs: xs, Ys: ys}
 	out.Sort()
 	return &out
 }
 
+//DuplicatePolicy - how NewBiVariateData treats repeated x-values
+type DuplicatePolicy int
+
+const (
+	//DuplicateKeep - keep every point (as MakeBiVariateData)
+	DuplicateKeep DuplicatePolicy = iota
+	//DuplicateError - return ErrDuplicate
+	DuplicateError
+	//DuplicateAverage - replace the points by one with the mean y-value
+	DuplicateAverage
+	//DuplicateFirst - keep the first of the points in input order
+	DuplicateFirst
+	//DuplicateLast - keep the last of the points in input order
+	DuplicateLast
+	//DuplicateJitter - spread the x-values apart by tiny steps
+	DuplicateJitter
+)
+
+//ErrDuplicate - repeated x-values
+var ErrDuplicate = errors.New("vec: duplicate x-value")
+
+//ErrNotFinite - a NaN or +/-Inf in input data
+var ErrNotFinite = errors.New("vec: NaN or infinite value in data")
+
+/*
+DataOptions - parameters for NewBiVariateData
+
+If 'Copy' is set the caller's slices are left untouched;
+otherwise they are sorted (and, for duplicates, compacted)
+in place, as in MakeBiVariateData. Either way nothing is
+written to them unless NewBiVariateData succeeds.
+*/
+type DataOptions struct {
+	Copy       bool
+	Duplicates DuplicatePolicy
+}
+
+/*
+Validates 'xs' and 'ys' and converts them into a sorted
+BiVariateData object
+
+Returns ErrLength if the slices differ in length, ErrNotFinite
+if any value is NaN or +/-Inf, and ErrDuplicate for repeated
+x-values under DuplicateError (or under DuplicateJitter, if
+they are too large to be spread apart). Sorting is stable,
+so "first" and "last" refer to the input order. Jittered
+x-values step up by 1E-9 times the x-range (less if the next
+x-value is closer).
+*/
+func NewBiVariateData(xs []float64, ys []float64, opts DataOptions) (*BiVariateData, error) {
+	if len(xs) != len(ys) {
+		return nil, ErrLength
+	}
+	for i := range xs {
+		if notRat(xs[i]) || notRat(ys[i]) {
+			return nil, ErrNotFinite
+		}
+	}
+
+	//work on copies, so a failure leaves the caller's slices alone
+	b := &BiVariateData{Xs: append([]float64{}, xs...), Ys: append([]float64{}, ys...)}
+	sort.Stable(b)
+	b.isSorted = true
+
+	switch opts.Duplicates {
+	case DuplicateKeep:
+	case DuplicateJitter:
+		if !jitter(b.Xs) {
+			return nil, ErrDuplicate
+		}
+	default:
+		if !b.compact(opts.Duplicates) {
+			return nil, ErrDuplicate
+		}
+	}
+
+	if !opts.Copy {
+		n := len(b.Xs)
+		copy(xs, b.Xs)
+		copy(ys, b.Ys)
+		b.Xs, b.Ys = xs[:n], ys[:n]
+	}
+	return b, nil
+}
+
+/*
+Compacts each run of equal x-values in the sorted 'b'
+into one point; false if there is a run under
+DuplicateError
+*/
+func (b *BiVariateData) compact(policy DuplicatePolicy) bool {
+	xs, ys := b.Xs, b.Ys
+	n := 0
+	for i := 0; i < len(xs); {
+		j := i + 1
+		for j < len(xs) && xs[j] == xs[i] {
+			j++
+		}
+		y := ys[i]
+		switch policy {
+		case DuplicateError:
+			if j-i > 1 {
+				return false
+			}
+		case DuplicateAverage:
+			for k := i + 1; k < j; k++ {
+				y += ys[k]
+			}
+			y /= float64(j - i)
+		case DuplicateLast:
+			y = ys[j-1]
+		}
+		xs[n], ys[n] = xs[i], y
+		n++
+		i = j
+	}
+	b.Xs, b.Ys = xs[:n], ys[:n]
+	return true
+}
+
+/*
+Spreads runs of equal values in the sorted slice 'xs'
+upwards; false if a step is too small to separate them
+(e.g. the x-values are large next to the gap to the next one)
+*/
+func jitter(xs []float64) bool {
+	if len(xs) < 2 {
+		return true
+	}
+	step := 1E-9 * (xs[len(xs)-1] - xs[0])
+	if step == 0 {
+		step = 1E-9 * math.Max(1.0, math.Abs(xs[0]))
+	}
+	for i := 0; i < len(xs); {
+		j := i + 1
+		for j < len(xs) && xs[j] == xs[i] {
+			j++
+		}
+		d := step
+		if j < len(xs) && float64(j-i)*d >= xs[j]-xs[i] {
+			d = (xs[j] - xs[i]) / float64(j-i+1)
+		}
+		for k := i + 1; k < j; k++ {
+			xs[k] = xs[i] + float64(k-i)*d
+			if xs[k] <= xs[k-1] {
+				return false
+			}
+		}
+		if j < len(xs) && xs[j-1] >= xs[j] {
+			return false
+		}
+		i = j
+	}
+	return true
+}
+
 //Len for Sort interface
 func (b *BiVariateData) Len() int {
 	return len(b.Xs)
@@ -46,6 +213,9 @@ func (b *BiVariateData) Less(i, j int) bool {
 func (b *BiVariateData) Swap(i, j int) {
 	b.Xs[i], b.Xs[j] = b.Xs[j], b.Xs[i]
 	b.Ys[i], b.Ys[j] = b.Ys[j], b.Ys[i]
+	if b.Errs != nil {
+		b.Errs[i], b.Errs[j] = b.Errs[j], b.Errs[i]
+	}
 	return
 }
 
@@ -67,6 +237,29 @@ func (b *BiVariateData) Sort() {
 	b.isSorted = true
 }
 
+/*
+Appends the point (x, y) to 'b'
+
+'b' stays sorted if 'x' is not less than the last x-value.
+Only LinearInterp, NearestInterp and StepInterp read the
+points directly and so see the new point immediately; the
+other interpolations store coefficients of the points they
+were built from and must be rebuilt after an Append (or use
+a StreamingInterpolation, which refits as points arrive).
+If 'b' carries Errs, the error of the new point is unknown
+(NaN).
+*/
+func (b *BiVariateData) Append(x float64, y float64) {
+	if n := len(b.Xs); n > 0 && x < b.Xs[n-1] {
+		b.isSorted = false
+	}
+	b.Xs = append(b.Xs, x)
+	b.Ys = append(b.Ys, y)
+	if b.Errs != nil {
+		b.Errs = append(b.Errs, math.NaN())
+	}
+}
+
 //Implement XYer interface for plotinum.plotter
 func (b *BiVariateData) XY(n int) (x, y float64) {
 	if n >= b.Len() || n < 0 {
@@ -81,27 +274,51 @@ func (b *BiVariateData) findXBounds(x float64) (int, int) {
 	if !b.isSorted {
 		b.Sort()
 	}
-	l := len(b.Xs)
+	i := bracket(b.Xs, x)
+	return i, i + 1
+}
+
+/*
+Returns the index of the interval of the ascending
+knots 'xs' that contains 'x', clamped so that
+the first and last intervals extend to +/-Inf
+*/
+func bracket(xs []float64, x float64) int {
+	l := len(xs)
 
 	//edge cases
-	if x >= b.Xs[l-1] {
-		return l - 2, l - 1
+	if x >= xs[l-1] {
+		return l - 2
 	}
-	if x <= b.Xs[0] {
-		return 0, 1
+	if x <= xs[0] {
+		return 0
 	}
 
-	out := 0
-	for b.Xs[out] < x && out < l-2 {
-		out++
+	//last knot at or below x (binary search)
+	lo, hi := 0, l-1
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		if xs[mid] <= x {
+			lo = mid
+		} else {
+			hi = mid
+		}
 	}
 
-	return out, out + 1
-
+	return lo
 }
 
-/* TODO:
-func DiscreetConvolve(dat []float64, conv []float64) []float64 {}
+/*
+Returns the running trapezoid-rule integral of 'b':
+the i-th y-value is the integral from Xs[0] to Xs[i]
 
-Returns a discreet convolution of 'conv' on 'dat'
+'b' is sorted in place; the result shares its x-values.
 */
+func CumulativeIntegral(b *BiVariateData) *BiVariateData {
+	b.Sort()
+	cum := make([]float64, len(b.Xs))
+	for i := 1; i < len(cum); i++ {
+		cum[i] = cum[i-1] + (b.Xs[i]-b.Xs[i-1])*(b.Ys[i]+b.Ys[i-1])/2.0
+	}
+	return &BiVariateData{Xs: b.Xs, Ys: cum, isSorted: true}
+}
@@ -0,0 +1,85 @@
+package vec
+
+import "testing"
+import "math"
+
+/*
+Test NewBiVariateData with each duplicate policy
+
+EDGE CASES:
+- Copy leaves the input slices untouched
+- jittered x-values are strictly increasing
+- DuplicateError without Copy fails before writing anything
+- jitter steps below the spacing of the floats fail
+- mismatched lengths, NaN and Inf are rejected
+*/
+func TestNewBiVariateData(t *testing.T) {
+	xs := []float64{2, 1, 2, 0, 2}
+	ys := []float64{5, 1, 6, 0, 7}
+	cases := []struct {
+		dup    DuplicatePolicy
+		xs, ys []float64
+	}{
+		{DuplicateKeep, []float64{0, 1, 2, 2, 2}, []float64{0, 1, 5, 6, 7}},
+		{DuplicateAverage, []float64{0, 1, 2}, []float64{0, 1, 6}},
+		{DuplicateFirst, []float64{0, 1, 2}, []float64{0, 1, 5}},
+		{DuplicateLast, []float64{0, 1, 2}, []float64{0, 1, 7}},
+	}
+	for _, c := range cases {
+		d, err := NewBiVariateData(xs, ys, DataOptions{Copy: true, Duplicates: c.dup})
+		if err != nil || len(d.Xs) != len(c.xs) {
+			t.Error("Policy", c.dup, "failed. Got:", d, err)
+			continue
+		}
+		for i := range c.xs {
+			if d.Xs[i] != c.xs[i] || d.Ys[i] != c.ys[i] {
+				t.Error("Policy", c.dup, "is wrong. Got:", d.Xs, d.Ys, "Expected:", c.xs, c.ys)
+				break
+			}
+		}
+	}
+	if xs[0] != 2 || ys[4] != 7 {
+		t.Error("Copy changed the input slices. Got:", xs, ys)
+	}
+
+	d, _ := NewBiVariateData(xs, ys, DataOptions{Copy: true, Duplicates: DuplicateJitter})
+	for i := 1; i < len(d.Xs); i++ {
+		if d.Xs[i] <= d.Xs[i-1] || math.Abs(d.Xs[i]-math.Round(d.Xs[i])) > 1E-8 {
+			t.Error("Jitter did not separate the x-values. Got:", d.Xs)
+		}
+	}
+	if s := CubicSpline(d); math.IsNaN(s.F(1.5)) {
+		t.Error("Spline of jittered data is NaN.")
+	}
+
+	if _, err := NewBiVariateData(xs, ys, DataOptions{Duplicates: DuplicateError}); err != ErrDuplicate {
+		t.Error("Expected ErrDuplicate. Got:", err)
+	}
+	if xs[0] != 2 || xs[1] != 1 || ys[4] != 7 {
+		t.Error("DuplicateError changed the input slices. Got:", xs, ys)
+	}
+
+	//no representable float between 1 and the next x-value
+	close := []float64{1, 1, 1 + 2.220446049250313E-16}
+	if _, err := NewBiVariateData(close, []float64{0, 1, 2}, DataOptions{Duplicates: DuplicateJitter}); err != ErrDuplicate {
+		t.Error("Expected ErrDuplicate for unjitterable x-values. Got:", err)
+	}
+	if close[1] != 1 {
+		t.Error("Failed jitter changed the input slices. Got:", close)
+	}
+
+	d, err := NewBiVariateData(xs, ys, DataOptions{Duplicates: DuplicateFirst})
+	if err != nil || len(d.Xs) != 3 || xs[0] != 0 || ys[2] != 5 || &d.Xs[0] != &xs[0] {
+		t.Error("Without Copy the input slices should be compacted in place. Got:", xs, ys)
+	}
+
+	if _, err := NewBiVariateData(xs, ys[:2], DataOptions{}); err != ErrLength {
+		t.Error("Expected ErrLength. Got:", err)
+	}
+	if _, err := NewBiVariateData([]float64{1, math.NaN()}, []float64{1, 2}, DataOptions{}); err != ErrNotFinite {
+		t.Error("Expected ErrNotFinite for NaN. Got:", err)
+	}
+	if _, err := NewBiVariateData([]float64{1, 2}, []float64{1, math.Inf(1)}, DataOptions{}); err != ErrNotFinite {
+		t.Error("Expected ErrNotFinite for Inf. Got:", err)
+	}
+}
@@ -0,0 +1,250 @@
+package vec
+
+import (
+	"errors"
+	"math"
+)
+
+/*
+Derivatives of sampled data
+
+Gradient() uses finite-difference stencils with weights
+from Fornberg's algorithm, so any grid spacing and order
+of accuracy are handled the same way. SavGolDeriv() fits a
+least-squares polynomial in a sliding window, which also
+smooths noise, but needs evenly spaced samples.
+*/
+
+//ErrSpacing - x-values that are not evenly spaced
+var ErrSpacing = errors.New("vec: x-values not evenly spaced")
+
+/*
+Returns the first derivative of the data in 'b' at each
+x-value, accurate to O(h^order)
+
+Each derivative uses the order+1 nearest points: central
+stencils inside the data and one-sided ones at the ends.
+order = 2 is the usual second-order central difference.
+Returns ErrParam if 'order' is less than one, and ErrLength
+if there are fewer than order+1 points. The x-values must
+be distinct.
+
+See:
+B. Fornberg, Math. Comp. 51(184), 699-706 (1988)
+*/
+func Gradient(b *BiVariateData, order int) (*BiVariateData, error) {
+	n := len(b.Xs)
+	if order < 1 {
+		return nil, ErrParam
+	}
+	if n < order+1 {
+		return nil, ErrLength
+	}
+	b.Sort()
+	out := &BiVariateData{Xs: append([]float64{}, b.Xs...), Ys: make([]float64, n), isSorted: true}
+	for k := range out.Ys {
+		lo := k - order/2
+		if lo < 0 {
+			lo = 0
+		}
+		if lo > n-order-1 {
+			lo = n - order - 1
+		}
+		w := fornberg(b.Xs[k], b.Xs[lo:lo+order+1], 1)[1]
+		for j, c := range w {
+			out.Ys[k] += c * b.Ys[lo+j]
+		}
+	}
+	return out, nil
+}
+
+/*
+Finite-difference weights c[d][j] for the d-th derivative
+(d <= m) at 'z' from values at the nodes 'xs'
+*/
+func fornberg(z float64, xs []float64, m int) [][]float64 {
+	n := len(xs)
+	c := zeros(m+1, n)
+	c[0][0] = 1.0
+	c1 := 1.0
+	c4 := xs[0] - z
+	for i := 1; i < n; i++ {
+		mn := i
+		if mn > m {
+			mn = m
+		}
+		c2 := 1.0
+		c5 := c4
+		c4 = xs[i] - z
+		for j := 0; j < i; j++ {
+			c3 := xs[i] - xs[j]
+			c2 *= c3
+			if j == i-1 {
+				for k := mn; k > 0; k-- {
+					c[k][i] = c1 * (float64(k)*c[k-1][i-1] - c5*c[k][i-1]) / c2
+				}
+				c[0][i] = -c1 * c5 * c[0][i-1] / c2
+			}
+			for k := mn; k > 0; k-- {
+				c[k][j] = (c4*c[k][j] - float64(k)*c[k-1][j]) / c3
+			}
+			c[0][j] = c4 * c[0][j] / c3
+		}
+		c1 = c2
+	}
+	return c
+}
+
+/*
+Returns the 'deriv'-th derivative of evenly spaced data by
+Savitzky-Golay filtering: a polynomial of degree 'poly' is
+fit by least squares to each 'window' points and
+differentiated at the centre point
+
+Near the ends the first (or last) window is used and the
+fit is differentiated at the end points themselves.
+Returns ErrParam if 'window' is even or not greater than
+'poly', or if 'deriv' is negative or greater than 'poly';
+ErrLength if 'window' is longer than the data;
+ErrSpacing if the x-values are not evenly spaced; and
+ErrSingular if the fit cannot be formed.
+
+See:
+A. Savitzky & M.J.E. Golay, Anal. Chem. 36(8), 1627-1639 (1964)
+*/
+func SavGolDeriv(b *BiVariateData, window int, poly int, deriv int) (*BiVariateData, error) {
+	n := len(b.Xs)
+	if window%2 == 0 || window <= poly || deriv < 0 || deriv > poly {
+		return nil, ErrParam
+	}
+	if window > n {
+		return nil, ErrLength
+	}
+	b.Sort()
+	h := (b.Xs[n-1] - b.Xs[0]) / float64(n-1)
+	for i := 1; i < n; i++ {
+		if math.Abs(b.Xs[i]-b.Xs[i-1]-h) > 1E-6*h {
+			return nil, ErrSpacing
+		}
+	}
+
+	w, err := savgolWeights(window, poly, deriv)
+	if err != nil {
+		return nil, err
+	}
+	out := &BiVariateData{Xs: append([]float64{}, b.Xs...), Ys: savgolApply(b.Ys, w), isSorted: true}
+	scale := math.Pow(h, float64(-deriv))
+	for k := range out.Ys {
+		out.Ys[k] *= scale
+	}
+	return out, nil
+}
+
+//Savitzky-Golay filter of 'ys' (len(ys) >= len(w)) with the weights from savgolWeights
+func savgolApply(ys []float64, w [][]float64) []float64 {
+	n := len(ys)
+	window := len(w)
+	half := window / 2
+	out := make([]float64, n)
+	for k := range out {
+		lo, pos := k-half, half
+		switch {
+		case k < half:
+			lo, pos = 0, k
+		case k >= n-half:
+			lo, pos = n-window, k-(n-window)
+		}
+		for j, c := range w[pos] {
+			out[k] += c * ys[lo+j]
+		}
+	}
+	return out
+}
+
+/*
+Weights w[pos][j] that give the 'deriv'-th derivative, at
+sample 'pos' of a window of 'window' unit-spaced samples,
+of the least-squares polynomial of degree 'poly' through
+them
+
+The fit uses polynomials orthogonal over the window
+(Gram polynomials), built by the Stieltjes recurrence on
+the samples scaled to [-1, 1], so no normal equations are
+formed. Returns ErrSingular if the basis degenerates.
+*/
+func savgolWeights(window int, poly int, deriv int) ([][]float64, error) {
+	c := float64(window-1) / 2
+	s := c
+	if s == 0 {
+		s = 1
+	}
+	us := make([]float64, window)
+	for j := range us {
+		us[j] = (float64(j) - c) / s
+	}
+
+	//p[k][j] = p_k(u_j), with p_{k+1} = (u - a[k])*p_k - b[k]*p_{k-1}
+	p := zeros(poly+1, window)
+	norms := make([]float64, poly+1)
+	a := make([]float64, poly+1)
+	b := make([]float64, poly+1)
+	for k := 0; k <= poly; k++ {
+		for j, u := range us {
+			switch k {
+			case 0:
+				p[k][j] = 1
+			case 1:
+				p[k][j] = (u - a[0]) * p[0][j]
+			default:
+				p[k][j] = (u-a[k-1])*p[k-1][j] - b[k-1]*p[k-2][j]
+			}
+		}
+		un := 0.0
+		for j, u := range us {
+			norms[k] += p[k][j] * p[k][j]
+			un += u * p[k][j] * p[k][j]
+		}
+		if !(norms[k] > 0) || math.IsInf(norms[k], 0) {
+			return nil, ErrSingular
+		}
+		a[k] = un / norms[k]
+		if k > 0 {
+			b[k] = norms[k] / norms[k-1]
+		}
+	}
+
+	scale := math.Pow(s, float64(-deriv))
+	w := zeros(window, window)
+	dp := zeros(poly+1, deriv+1) //dp[k][m] = m-th derivative of p_k at u_pos
+	for pos := range w {
+		u := us[pos]
+		for k := 0; k <= poly; k++ {
+			for m := 0; m <= deriv; m++ {
+				if k == 0 {
+					dp[k][m] = 0
+					if m == 0 {
+						dp[k][m] = 1
+					}
+					continue
+				}
+				dp[k][m] = (u - a[k-1]) * dp[k-1][m]
+				if m > 0 {
+					dp[k][m] += float64(m) * dp[k-1][m-1]
+				}
+				if k > 1 {
+					dp[k][m] -= b[k-1] * dp[k-2][m]
+				}
+			}
+		}
+		for j := range w[pos] {
+			for k := 0; k <= poly; k++ {
+				w[pos][j] += p[k][j] * dp[k][deriv] / norms[k]
+			}
+			w[pos][j] *= scale
+			if math.IsNaN(w[pos][j]) || math.IsInf(w[pos][j], 0) {
+				return nil, ErrSingular
+			}
+		}
+	}
+	return w, nil
+}
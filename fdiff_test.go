@@ -0,0 +1,122 @@
+package vec
+
+import "testing"
+import "math"
+
+/*
+Test finite-difference gradients
+
+- exact for polynomials of degree 'order' on uneven points
+- second order converges at O(h^2)
+
+EDGE CASES:
+- too few points returns ErrLength
+- a zero order returns ErrParam
+*/
+func TestGradient(t *testing.T) {
+	xs := []float64{0, 0.1, 0.35, 0.5, 0.9, 1.0, 1.6, 2}
+	for _, order := range []int{1, 2, 4, 5} {
+		p := func(x float64) float64 { return math.Pow(x, float64(order)) - x }
+		dp := func(x float64) float64 { return float64(order)*math.Pow(x, float64(order-1)) - 1 }
+		ys := make([]float64, len(xs))
+		for i, x := range xs {
+			ys[i] = p(x)
+		}
+		g, _ := Gradient(MakeBiVariateData(append([]float64{}, xs...), ys), order)
+		for i, x := range g.Xs {
+			if math.Abs(g.Ys[i]-dp(x)) > 1E-10 {
+				t.Error("Gradient of order", order, "is wrong at", x, "Got:", g.Ys[i], "Expected:", dp(x))
+			}
+		}
+	}
+
+	errAt := func(n int) float64 {
+		xs := Arange(0, 1, n)
+		ys := Arange(0, 1, n)
+		PPmap(math.Sin, ys)
+		g, _ := Gradient(MakeBiVariateData(xs, ys), 2)
+		e := 0.0
+		for i, x := range g.Xs {
+			e = math.Max(e, math.Abs(g.Ys[i]-math.Cos(x)))
+		}
+		return e
+	}
+	if r := errAt(20) / errAt(40); r < 3.5 || r > 4.5 {
+		t.Error("Gradient is not second order. Got:", r, "Expected:", 4)
+	}
+
+	if _, err := Gradient(MakeBiVariateData([]float64{0, 1}, []float64{0, 1}), 2); err != ErrLength {
+		t.Error("Expected ErrLength with too few points. Got:", err)
+	}
+	if _, err := Gradient(MakeBiVariateData([]float64{0, 1}, []float64{0, 1}), 0); err != ErrParam {
+		t.Error("Expected ErrParam for a zero order. Got:", err)
+	}
+}
+
+/*
+Test Savitzky-Golay derivatives
+
+- exact for polynomials up to the fit degree
+- the classic 5-point quadratic weights
+
+EDGE CASES:
+- wide windows with high fit degrees
+- bad windows and derivative orders return ErrParam
+- uneven spacing returns ErrSpacing
+*/
+func TestSavGolDeriv(t *testing.T) {
+	xs := Arange(-1, 2, 31)
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = 2*x*x*x - x*x + 3
+	}
+	d := MakeBiVariateData(xs, ys)
+	s0, _ := SavGolDeriv(d, 7, 3, 0)
+	s1, _ := SavGolDeriv(d, 7, 3, 1)
+	s2, _ := SavGolDeriv(d, 7, 3, 2)
+	for i, x := range xs {
+		if math.Abs(s0.Ys[i]-ys[i]) > 1E-10 || math.Abs(s1.Ys[i]-(6*x*x-2*x)) > 1E-9 || math.Abs(s2.Ys[i]-(12*x-2)) > 1E-7 {
+			t.Error("SavGolDeriv is wrong at", x, "Got:", s0.Ys[i], s1.Ys[i], s2.Ys[i], "Expected:", ys[i], 6*x*x-2*x, 12*x-2)
+		}
+	}
+
+	ws, _ := savgolWeights(5, 2, 0)
+	want := []float64{-3, 12, 17, 12, -3}
+	for i, w := range ws[2] {
+		if math.Abs(35*w-want[i]) > 1E-12 {
+			t.Error("savgolWeights is wrong. Got:", ws[2], "Expected:", want, "/ 35")
+			break
+		}
+	}
+
+	//wide, high-degree windows still reproduce a constant and a line
+	for _, wp := range [][2]int{{21, 8}, {51, 10}, {101, 12}, {201, 8}} {
+		xs := Arange(0, 1, 2*wp[0])
+		ones := make([]float64, len(xs))
+		for i := range ones {
+			ones[i] = 1
+		}
+		s0, err0 := SavGolDeriv(MakeBiVariateData(xs, ones), wp[0], wp[1], 0)
+		s1, err1 := SavGolDeriv(MakeBiVariateData(xs, append([]float64{}, xs...)), wp[0], wp[1], 1)
+		if err0 != nil || err1 != nil {
+			t.Error("SavGolDeriv failed for", wp, "Got:", err0, err1)
+			continue
+		}
+		for i := range xs {
+			if math.Abs(s0.Ys[i]-1) > 1E-9 || math.Abs(s1.Ys[i]-1) > 1E-7 {
+				t.Error("SavGolDeriv", wp, "is wrong at", i, "Got:", s0.Ys[i], s1.Ys[i], "Expected:", 1, 1)
+				break
+			}
+		}
+	}
+
+	if _, err := SavGolDeriv(d, 4, 2, 1); err != ErrParam {
+		t.Error("Expected ErrParam for an even window. Got:", err)
+	}
+	if _, err := SavGolDeriv(d, 5, 2, 3); err != ErrParam {
+		t.Error("Expected ErrParam for a derivative above the fit degree. Got:", err)
+	}
+	if _, err := SavGolDeriv(MakeBiVariateData([]float64{0, 1, 3, 4, 5}, []float64{0, 1, 2, 3, 4}), 3, 1, 1); err != ErrSpacing {
+		t.Error("Expected ErrSpacing for uneven spacing. Got:", err)
+	}
+}
@@ -0,0 +1,320 @@
+package vec
+
+import "math"
+
+/*
+Two-dimensional interpolation of tabulated data
+
+Grid holds z-values on a rectangular grid. Bilinear and
+Bicubic build a GridInterpolation, whose F method is a
+BiMathop:
+
+	g, err := vec.Bicubic(&vec.Grid{Xs: temps, Ys: pressures, Zs: table})
+	out := vec.PVecOperation(g.F, ts, ps)
+
+Each grid cell is stored as a polynomial in the cell
+coordinates t = (x - Xs[i])/hx and u = (y - Ys[j])/hy.
+Points outside the grid are extrapolated along the edge cells.
+*/
+
+//Grid - Zs[i][j] is the value at (Xs[i], Ys[j]); Xs and Ys must be ascending
+type Grid struct {
+	Xs []float64
+	Ys []float64
+	Zs [][]float64
+}
+
+/*
+GridInterpolation type def
+points to a Grid and contains the polynomial
+coefficients of every cell; cells[i][j][4*p+q]
+multiplies t^p * u^q
+*/
+type GridInterpolation struct {
+	grid  *Grid
+	cells [][][16]float64
+	cubic bool
+}
+
+//checks that 'g' has at least 2x2 strictly ascending knots and a matching table
+func (g *Grid) check() error {
+	if len(g.Xs) < 2 || len(g.Ys) < 2 || len(g.Zs) != len(g.Xs) {
+		return ErrLength
+	}
+	for _, row := range g.Zs {
+		if len(row) != len(g.Ys) {
+			return ErrLength
+		}
+	}
+	if err := checkAxis(g.Xs); err != nil {
+		return err
+	}
+	return checkAxis(g.Ys)
+}
+
+//checks that the knots 'ax' are finite and strictly increasing
+func checkAxis(ax []float64) error {
+	for i, x := range ax {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return ErrNotFinite
+		}
+		if i > 0 && x <= ax[i-1] {
+			return ErrUnordered
+		}
+	}
+	return nil
+}
+
+/*
+Constructs a bilinear interpolation of 'g'
+
+Returns ErrLength if 'g' is smaller than 2x2 or ragged,
+ErrNotFinite if an axis holds NaN or Inf, and
+ErrUnordered if its axes are not strictly ascending.
+*/
+func Bilinear(g *Grid) (*GridInterpolation, error) {
+	if err := g.check(); err != nil {
+		return nil, err
+	}
+	out := newGridInterpolation(g)
+	for i := range out.cells {
+		for j := range out.cells[i] {
+			f00, f10 := g.Zs[i][j], g.Zs[i+1][j]
+			f01, f11 := g.Zs[i][j+1], g.Zs[i+1][j+1]
+			a := &out.cells[i][j]
+			a[0] = f00
+			a[4] = f10 - f00
+			a[1] = f01 - f00
+			a[5] = f11 - f10 - f01 + f00
+		}
+	}
+	return out, nil
+}
+
+/*
+Constructs a bicubic interpolation of 'g'
+
+Partial derivatives at the knots (dz/dx, dz/dy and
+d2z/dxdy) are estimated by second-order finite differences,
+and each cell is the bicubic Hermite patch matching the
+values and derivatives at its corners. The result is
+continuously differentiable and reproduces quadratics exactly.
+
+Returns the same errors as Bilinear.
+*/
+func Bicubic(g *Grid) (*GridInterpolation, error) {
+	if err := g.check(); err != nil {
+		return nil, err
+	}
+	nx, ny := len(g.Xs), len(g.Ys)
+
+	//derivatives along x, y, and the cross derivative
+	fx := make([][]float64, nx)
+	fy := make([][]float64, nx)
+	fxy := make([][]float64, nx)
+	col := make([]float64, nx)
+	for i := range fx {
+		fx[i] = make([]float64, ny)
+		fxy[i] = make([]float64, ny)
+		fy[i] = fdSlopes(g.Ys, g.Zs[i])
+	}
+	for j := 0; j < ny; j++ {
+		for i := range col {
+			col[i] = g.Zs[i][j]
+		}
+		d := fdSlopes(g.Xs, col)
+		for i := range col {
+			fx[i][j] = d[i]
+		}
+	}
+	for i := range fxy {
+		fxy[i] = fdSlopes(g.Ys, fx[i])
+	}
+
+	//cubic Hermite basis (value left, value right, slope left, slope right)
+	hb := [4][4]float64{{1, 0, -3, 2}, {0, 0, 3, -2}, {0, 1, -2, 1}, {0, 0, -1, 1}}
+
+	out := newGridInterpolation(g)
+	out.cubic = true
+	for i := range out.cells {
+		hx := g.Xs[i+1] - g.Xs[i]
+		for j := range out.cells[i] {
+			hy := g.Ys[j+1] - g.Ys[j]
+
+			//v[k][l]: k, l index the basis functions along x and y
+			var v [4][4]float64
+			for di := 0; di < 2; di++ {
+				for dj := 0; dj < 2; dj++ {
+					v[di][dj] = g.Zs[i+di][j+dj]
+					v[2+di][dj] = fx[i+di][j+dj] * hx
+					v[di][2+dj] = fy[i+di][j+dj] * hy
+					v[2+di][2+dj] = fxy[i+di][j+dj] * hx * hy
+				}
+			}
+
+			a := &out.cells[i][j]
+			for k := 0; k < 4; k++ {
+				for l := 0; l < 4; l++ {
+					if v[k][l] == 0 {
+						continue
+					}
+					for p := 0; p < 4; p++ {
+						for q := 0; q < 4; q++ {
+							a[4*p+q] += hb[k][p] * hb[l][q] * v[k][l]
+						}
+					}
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+func newGridInterpolation(g *Grid) *GridInterpolation {
+	cells := make([][][16]float64, len(g.Xs)-1)
+	for i := range cells {
+		cells[i] = make([][16]float64, len(g.Ys)-1)
+	}
+	return &GridInterpolation{grid: g, cells: cells}
+}
+
+/*
+Second-order finite-difference estimates of dy/dx
+at each knot (one-sided three-point formulas at the ends)
+*/
+func fdSlopes(xs []float64, ys []float64) []float64 {
+	n := len(xs)
+	h, del := secants(xs, ys)
+	m := make([]float64, n)
+	if n == 2 {
+		m[0], m[1] = del[0], del[0]
+		return m
+	}
+	for i := 1; i < n-1; i++ {
+		m[i] = (h[i]*del[i-1] + h[i-1]*del[i]) / (h[i-1] + h[i])
+	}
+	m[0] = ((2*h[0]+h[1])*del[0] - h[0]*del[1]) / (h[0] + h[1])
+	m[n-1] = ((2*h[n-2]+h[n-3])*del[n-2] - h[n-2]*del[n-3]) / (h[n-2] + h[n-3])
+	return m
+}
+
+//Returns the extent of the grid: (x_min, x_max, y_min, y_max)
+func (gi *GridInterpolation) Domain() (float64, float64, float64, float64) {
+	g := gi.grid
+	return g.Xs[0], g.Xs[len(g.Xs)-1], g.Ys[0], g.Ys[len(g.Ys)-1]
+}
+
+/*
+Evaluates the (dx, dy)-th partial derivative of the
+cell containing (x, y)
+*/
+func (gi *GridInterpolation) eval(x float64, y float64, dx int, dy int) float64 {
+	if notRat(x) || notRat(y) {
+		return math.NaN()
+	}
+	g := gi.grid
+	i, j := bracket(g.Xs, x), bracket(g.Ys, y)
+	hx, hy := g.Xs[i+1]-g.Xs[i], g.Ys[j+1]-g.Ys[j]
+	t, u := (x-g.Xs[i])/hx, (y-g.Ys[j])/hy
+	a := &gi.cells[i][j]
+
+	out := 0.0
+	for p := dx; p < 4; p++ {
+		for q := dy; q < 4; q++ {
+			out += a[4*p+q] * dpow(t, p, dx) * dpow(u, q, dy)
+		}
+	}
+	return out / (math.Pow(hx, float64(dx)) * math.Pow(hy, float64(dy)))
+}
+
+//d-th derivative of t^p
+func dpow(t float64, p int, d int) float64 {
+	c := 1.0
+	for k := 0; k < d; k++ {
+		c *= float64(p - k)
+	}
+	for k := d; k < p; k++ {
+		c *= t
+	}
+	return c
+}
+
+//Returns the interpolated value at (x, y)
+func (gi *GridInterpolation) F(x float64, y float64) float64 {
+	return gi.eval(x, y, 0, 0)
+}
+
+//Partial derivative with respect to x at (x, y)
+func (gi *GridInterpolation) DX(x float64, y float64) float64 {
+	return gi.eval(x, y, 1, 0)
+}
+
+//Partial derivative with respect to y at (x, y)
+func (gi *GridInterpolation) DY(x float64, y float64) float64 {
+	return gi.eval(x, y, 0, 1)
+}
+
+//Mixed partial derivative d2f/dxdy at (x, y)
+func (gi *GridInterpolation) DXY(x float64, y float64) float64 {
+	return gi.eval(x, y, 1, 1)
+}
+
+/*
+Returns the integral over the rectangle
+[x1, x2] x [y1, y2]
+
+(reversed bounds flip the sign, as in one dimension)
+*/
+func (gi *GridInterpolation) Integral(x1 float64, x2 float64, y1 float64, y2 float64) float64 {
+	if notRat(x1) || notRat(x2) || notRat(y1) || notRat(y2) {
+		return math.NaN()
+	}
+	g := gi.grid
+	sgn := 1.0
+	if x1 > x2 {
+		x1, x2, sgn = x2, x1, -sgn
+	}
+	if y1 > y2 {
+		y1, y2, sgn = y2, y1, -sgn
+	}
+
+	out := 0.0
+	for _, px := range cellPieces(g.Xs, x1, x2) {
+		for _, py := range cellPieces(g.Ys, y1, y2) {
+			a := &gi.cells[px.i][py.i]
+			for p := 0; p < 4; p++ {
+				ip := (math.Pow(px.t2, float64(p+1)) - math.Pow(px.t1, float64(p+1))) / float64(p+1)
+				for q := 0; q < 4; q++ {
+					iq := (math.Pow(py.t2, float64(q+1)) - math.Pow(py.t1, float64(q+1))) / float64(q+1)
+					out += a[4*p+q] * ip * iq * px.h * py.h
+				}
+			}
+		}
+	}
+	return sgn * out
+}
+
+//part of knot interval 'i' (width 'h'), from 't1' to 't2' in cell coordinates
+type cellPiece struct {
+	i      int
+	t1, t2 float64
+	h      float64
+}
+
+//splits [a, b] (a <= b) at the knots 'xs'
+func cellPieces(xs []float64, a float64, b float64) []cellPiece {
+	ia, ib := bracket(xs, a), bracket(xs, b)
+	out := make([]cellPiece, 0, ib-ia+1)
+	for i := ia; i <= ib; i++ {
+		h := xs[i+1] - xs[i]
+		lo, hi := xs[i], xs[i+1]
+		if i == ia {
+			lo = a
+		}
+		if i == ib {
+			hi = b
+		}
+		out = append(out, cellPiece{i: i, t1: (lo - xs[i]) / h, t2: (hi - xs[i]) / h, h: h})
+	}
+	return out
+}
@@ -0,0 +1,89 @@
+package vec
+
+import "testing"
+import "math"
+
+//tabulates 'f' on an uneven grid
+func makeGrid(f BiMathop) *Grid {
+	g := &Grid{Xs: []float64{0, 0.5, 1.5, 2, 3}, Ys: []float64{-1, 0, 0.25, 1}}
+	g.Zs = make([][]float64, len(g.Xs))
+	for i, x := range g.Xs {
+		g.Zs[i] = make([]float64, len(g.Ys))
+		for j, y := range g.Ys {
+			g.Zs[i][j] = f(x, y)
+		}
+	}
+	return g
+}
+
+/*
+Test bilinear interpolation
+
+- reproduces a bilinear function and its partials
+- integrates it exactly over a rectangle crossing cells
+- works as a BiMathop in PVecOperation
+*/
+func TestBilinear(t *testing.T) {
+	f := func(x float64, y float64) float64 {
+		return 1 + 2*x - 3*y + 0.5*x*y
+	}
+	gi, _ := Bilinear(makeGrid(f))
+	for _, p := range [][2]float64{{0.2, -0.3}, {1.7, 0.1}, {2.9, 0.9}} {
+		x, y := p[0], p[1]
+		if math.Abs(gi.F(x, y)-f(x, y)) > 1E-13 {
+			t.Error("Bilinear inaccurate at", x, y, "Got:", gi.F(x, y), "Expected:", f(x, y))
+		}
+		if math.Abs(gi.DX(x, y)-(2+0.5*y)) > 1E-13 || math.Abs(gi.DY(x, y)-(-3+0.5*x)) > 1E-13 || math.Abs(gi.DXY(x, y)-0.5) > 1E-13 {
+			t.Error("Bilinear partial derivatives inaccurate at", x, y)
+		}
+	}
+
+	//exact: x from 0.2 to 2.5, y from -0.5 to 0.75
+	ix, iy := 2.3, 1.25
+	mx, my := (0.2+2.5)/2, (-0.5+0.75)/2
+	exact := ix * iy * f(mx, my)
+	if relativeError(gi.Integral(0.2, 2.5, -0.5, 0.75), exact) > 1E-13 {
+		t.Error("Bilinear integral inaccurate. Got:", gi.Integral(0.2, 2.5, -0.5, 0.75), "Expected:", exact)
+	}
+
+	out := PVecOperation(gi.F, []float64{0.2, 1.7}, []float64{-0.3, 0.1})
+	if math.Abs(out[1]-f(1.7, 0.1)) > 1E-13 {
+		t.Error("GridInterpolation.F doesn't work as a BiMathop")
+	}
+
+	if _, err := Bilinear(&Grid{Xs: []float64{0, 1}, Ys: []float64{0, 1}, Zs: [][]float64{{0, 1}}}); err != ErrLength {
+		t.Error("Bilinear accepted a ragged grid. Got:", err)
+	}
+	if _, err := Bicubic(&Grid{Xs: []float64{1, 0}, Ys: []float64{0, 1}, Zs: [][]float64{{0, 1}, {1, 2}}}); err != ErrUnordered {
+		t.Error("Bicubic accepted unsorted axes. Got:", err)
+	}
+	if _, err := Bilinear(&Grid{Xs: []float64{0, 0, 1}, Ys: []float64{0, 1}, Zs: [][]float64{{0, 1}, {1, 2}, {2, 3}}}); err != ErrUnordered {
+		t.Error("Bilinear accepted repeated knots. Got:", err)
+	}
+	if _, err := Bilinear(&Grid{Xs: []float64{0, 1}, Ys: []float64{0, math.NaN()}, Zs: [][]float64{{0, 1}, {1, 2}}}); err != ErrNotFinite {
+		t.Error("Bilinear accepted a NaN knot. Got:", err)
+	}
+}
+
+//Bicubic interpolation reproduces quadratics
+func TestBicubic(t *testing.T) {
+	f := func(x float64, y float64) float64 {
+		return x*x - 2*x*y + 3*y*y + x - 1
+	}
+	gi, _ := Bicubic(makeGrid(f))
+	for _, p := range [][2]float64{{0.2, -0.3}, {1.7, 0.1}, {2.9, 0.9}, {3.5, -1.5}} {
+		x, y := p[0], p[1]
+		if math.Abs(gi.F(x, y)-f(x, y)) > 1E-12 {
+			t.Error("Bicubic inaccurate at", x, y, "Got:", gi.F(x, y), "Expected:", f(x, y))
+		}
+		if math.Abs(gi.DX(x, y)-(2*x-2*y+1)) > 1E-12 || math.Abs(gi.DY(x, y)-(6*y-2*x)) > 1E-12 || math.Abs(gi.DXY(x, y)+2) > 1E-12 {
+			t.Error("Bicubic partial derivatives inaccurate at", x, y)
+		}
+	}
+
+	//integral over the whole grid [0,3]x[-1,1]
+	exact := 18.0 - 0 + 3*3*(2.0/3) + 9.0 - 6.0
+	if relativeError(gi.Integral(0, 3, -1, 1), exact) > 1E-13 {
+		t.Error("Bicubic integral inaccurate. Got:", gi.Integral(0, 3, -1, 1), "Expected:", exact)
+	}
+}
@@ -0,0 +1,204 @@
+package vec
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+Hermite interpolation from known derivatives
+
+When the derivative at each point is already known (e.g.
+the state and its rate from an ODE solver), there is no
+system to solve: each segment is the unique cubic matching
+the values and first derivatives at its ends, or the unique
+quintic that also matches the second derivatives.
+
+NOTE: as in MakeBiVariateData, the input slices are sorted
+together by x-value in place.
+*/
+
+/*
+Constructs a cubic Hermite spline through 'ys' with
+first derivatives 'dys' at the points 'xs'
+
+Returns ErrLength if the slices differ in length or there
+are fewer than two points, and ErrDuplicate if an x-value
+is repeated.
+*/
+func HermiteSpline(xs []float64, ys []float64, dys []float64) (*CubicSplineInterpolation, error) {
+	d, err := hermiteData(xs, ys, dys)
+	if err != nil {
+		return nil, err
+	}
+	return hermiteSpline(d, dys), nil
+}
+
+//QuinticHermiteInterpolation type def
+type QuinticHermiteInterpolation struct {
+	data *BiVariateData
+	dys  []float64
+	ddys []float64
+	ext  Extrapolation
+}
+
+/*
+Constructs a quintic Hermite interpolation through 'ys'
+with first derivatives 'dys' and second derivatives 'ddys'
+at the points 'xs'; the result has a continuous second
+derivative
+
+Returns the same errors as HermiteSpline().
+*/
+func QuinticHermite(xs []float64, ys []float64, dys []float64, ddys []float64) (*QuinticHermiteInterpolation, error) {
+	d, err := hermiteData(xs, ys, dys, ddys)
+	if err != nil {
+		return nil, err
+	}
+	return &QuinticHermiteInterpolation{data: d, dys: dys, ddys: ddys}, nil
+}
+
+//checks and sorts the columns of a Hermite interpolation
+func hermiteData(xs []float64, ys []float64, derivs ...[]float64) (*BiVariateData, error) {
+	N := len(xs)
+	if N < 2 || len(ys) != N {
+		return nil, ErrLength
+	}
+	cols := append([][]float64{ys}, derivs...)
+	for _, c := range derivs {
+		if len(c) != N {
+			return nil, ErrLength
+		}
+	}
+	if !sort.Float64sAreSorted(xs) {
+		sort.Sort(columns{xs, cols})
+	}
+	for i := 1; i < N; i++ {
+		if xs[i] == xs[i-1] {
+			return nil, ErrDuplicate
+		}
+	}
+	return &BiVariateData{Xs: xs, Ys: ys, isSorted: true}, nil
+}
+
+//sort.Interface over 'xs' and parallel slices 'cols'
+type columns struct {
+	xs   []float64
+	cols [][]float64
+}
+
+func (c columns) Len() int {
+	return len(c.xs)
+}
+
+func (c columns) Less(i, j int) bool {
+	return c.xs[i] < c.xs[j]
+}
+
+func (c columns) Swap(i, j int) {
+	c.xs[i], c.xs[j] = c.xs[j], c.xs[i]
+	for _, col := range c.cols {
+		col[i], col[j] = col[j], col[i]
+	}
+}
+
+/*
+Returns a copy of 'q' that uses the policy 'ext' outside
+the data endpoints; 'q' itself is unchanged
+*/
+func (q *QuinticHermiteInterpolation) Extrapolate(ext Extrapolation) *QuinticHermiteInterpolation {
+	out := *q
+	out.ext = ext
+	return &out
+}
+
+//Returns the first and last x-values of the data
+func (q *QuinticHermiteInterpolation) Domain() (float64, float64) {
+	return q.data.Xs[0], q.data.Xs[len(q.data.Xs)-1]
+}
+
+/*
+Returns the coefficients c[k] of t^k, with
+t = (x - Xs[i])/h, on segment 'i' and its width 'h'
+*/
+func (q *QuinticHermiteInterpolation) segment(i int) (c [6]float64, h float64) {
+	xs, ys := q.data.Xs, q.data.Ys
+	h = xs[i+1] - xs[i]
+	c[0] = ys[i]
+	c[1] = h * q.dys[i]
+	c[2] = h * h * q.ddys[i] / 2.0
+
+	//match value, slope and curvature at t = 1
+	A := ys[i+1] - c[0] - c[1] - c[2]
+	B := h*q.dys[i+1] - c[1] - 2*c[2]
+	C := h*h*q.ddys[i+1] - 2*c[2]
+	c[3] = 10*A - 4*B + C/2.0
+	c[4] = -15*A + 7*B - C
+	c[5] = 6*A - 3*B + C/2.0
+	return
+}
+
+//'deriv'-th derivative with respect to x at 'x'
+func (q *QuinticHermiteInterpolation) eval(x float64, deriv int) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(q, q.ext, x, deriv); ok {
+		return out
+	}
+	i, _ := q.data.findXBounds(x)
+	c, h := q.segment(i)
+	t := (x - q.data.Xs[i]) / h
+
+	//Horner's rule on the differentiated polynomial
+	out := 0.0
+	for k := 5; k >= deriv; k-- {
+		f := 1.0
+		for j := 0; j < deriv; j++ {
+			f *= float64(k - j)
+		}
+		out = out*t + f*c[k]
+	}
+	return out / math.Pow(h, float64(deriv))
+}
+
+//Returns the interpolated value at 'x'
+func (q *QuinticHermiteInterpolation) F(x float64) float64 {
+	return q.eval(x, 0)
+}
+
+//First derivative evaluated at 'x'
+func (q *QuinticHermiteInterpolation) DF(x float64) float64 {
+	return q.eval(x, 1)
+}
+
+//Second derivative evaluated at 'x'
+func (q *QuinticHermiteInterpolation) DDF(x float64) float64 {
+	return q.eval(x, 2)
+}
+
+//Returns the definite integral from 'a' to 'b'
+func (q *QuinticHermiteInterpolation) Integral(a float64, b float64) float64 {
+	if notRat(a) || notRat(b) {
+		return math.NaN()
+	}
+	if a > b {
+		return -q.Integral(b, a)
+	}
+	if out, ok := extrapIntegral(q, q.ext, a, b); ok {
+		return out
+	}
+
+	return segSum(q.data, a, b, func(i int, x1 float64, x2 float64) float64 {
+		c, h := q.segment(i)
+		prim := func(x float64) float64 {
+			t := (x - q.data.Xs[i]) / h
+			out := 0.0
+			for k := 5; k >= 0; k-- {
+				out = out*t + c[k]/float64(k+1)
+			}
+			return out * t
+		}
+		return h * (prim(x2) - prim(x1))
+	})
+}
@@ -0,0 +1,71 @@
+package vec
+
+import "testing"
+import "math"
+
+/*
+Test cubic Hermite splines
+
+- exact for cubics, from unsorted points
+
+EDGE CASES:
+- mismatched slices return ErrLength
+- repeated x-values return ErrDuplicate
+*/
+func TestHermiteSpline(t *testing.T) {
+	p := func(x float64) float64 { return x*x*x - 2*x + 1 }
+	dp := func(x float64) float64 { return 3*x*x - 2 }
+	xs := []float64{2, 0, 0.5, 1.5, 3}
+	ys := make([]float64, len(xs))
+	dys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i], dys[i] = p(x), dp(x)
+	}
+	s, _ := HermiteSpline(xs, ys, dys)
+	for _, x := range Arange(-1, 4, 11) {
+		if math.Abs(s.F(x)-p(x)) > 1E-10 || math.Abs(s.DF(x)-dp(x)) > 1E-10 {
+			t.Error("Hermite spline is wrong at", x, "Got:", s.F(x), s.DF(x), "Expected:", p(x), dp(x))
+		}
+	}
+
+	if _, err := HermiteSpline(xs, ys, dys[:2]); err != ErrLength {
+		t.Error("Expected ErrLength for mismatched slices. Got:", err)
+	}
+	if _, err := HermiteSpline([]float64{1, 1}, []float64{0, 1}, []float64{0, 0}); err != ErrDuplicate {
+		t.Error("Expected ErrDuplicate for repeated x-values. Got:", err)
+	}
+}
+
+/*
+Test quintic Hermite interpolation
+
+- exact for quintics, with first and second derivatives
+- integrates exactly
+*/
+func TestQuinticHermite(t *testing.T) {
+	p := func(x float64) float64 { return x*x*x*x*x - x*x*x + 2 }
+	dp := func(x float64) float64 { return 5*x*x*x*x - 3*x*x }
+	ddp := func(x float64) float64 { return 20*x*x*x - 6*x }
+	xs := []float64{1, -1, 0.25, 0.5}
+	ys := make([]float64, len(xs))
+	dys := make([]float64, len(xs))
+	ddys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i], dys[i], ddys[i] = p(x), dp(x), ddp(x)
+	}
+	q, _ := QuinticHermite(xs, ys, dys, ddys)
+	for _, x := range Arange(-1, 1, 13) {
+		if relativeError(q.F(x), p(x)) > 1E-10 && math.Abs(q.F(x)-p(x)) > 1E-10 {
+			t.Error("Quintic Hermite is wrong at", x, "Got:", q.F(x), "Expected:", p(x))
+		}
+		if math.Abs(q.DF(x)-dp(x)) > 1E-9 || math.Abs(q.DDF(x)-ddp(x)) > 1E-8 {
+			t.Error("Quintic Hermite derivatives are wrong at", x, "Got:", q.DF(x), q.DDF(x), "Expected:", dp(x), ddp(x))
+		}
+	}
+
+	//integral of x^5 - x^3 + 2 from -1 to 0.8
+	prim := func(x float64) float64 { return math.Pow(x, 6)/6 - math.Pow(x, 4)/4 + 2*x }
+	if math.Abs(q.Integral(-1, 0.8)-(prim(0.8)-prim(-1))) > 1E-12 {
+		t.Error("Quintic Hermite integral is wrong. Got:", q.Integral(-1, 0.8), "Expected:", prim(0.8)-prim(-1))
+	}
+}
@@ -1,6 +1,9 @@
 package vec
 
-import "math"
+import (
+	"errors"
+	"math"
+)
 
 /*
 Return a slice of length N
@@ -36,46 +39,181 @@ points to a BiVariateData container and contains
 an array of coefficients used for evaluating
 the interpolation, its derivatives, and its
 definite integral
+
+(coeffs[i] is the first derivative at Xs[i] scaled
+by the width of the segment starting at Xs[i]; the
+last knot borrows the width of the last segment)
 */
 type CubicSplineInterpolation struct {
 	data   *BiVariateData
 	coeffs []float64
+	ext    Extrapolation
+	adj    func(wy []float64, wD []float64) []float64 //see linearSpline
+}
+
+//EndCondition - boundary condition used to close a cubic spline
+type EndCondition int
+
+const (
+	//Natural - zero second derivative at both ends
+	Natural EndCondition = iota
+	//Clamped - first derivatives at the ends are supplied by the caller
+	Clamped
+	//NotAKnot - third derivative is continuous across the second and second-to-last knots
+	NotAKnot
+	//Periodic - first and second derivatives wrap around (requires Ys[0] == Ys[N-1])
+	Periodic
+)
+
+/*
+SplineEnds describes the end conditions of a cubic spline.
+'Left' and 'Right' are the end slopes used by Clamped
+and are ignored otherwise.
+*/
+type SplineEnds struct {
+	Cond  EndCondition
+	Left  float64
+	Right float64
 }
 
+//ErrPeriodic - periodic end conditions on data whose ends differ
+var ErrPeriodic = errors.New("vec: first and last y-values differ for periodic spline")
+
 /*
 Default constructor for CubicSplineInterpolation
 Returns a fully-formed CubicSplineInterpolation from
 the data containe in 'd'
 
-*Note: this is a 'natural' cubic spline (meaning that
-points evaluated outside the data endpoints are extrapolated
-linearly)
+*Note: this is a 'natural' cubic spline (zero curvature
+at the data endpoints). Points evaluated outside the
+data endpoints are extrapolated along the end segments.
 
-Feeds values to getCubicSplinCoeffs() that are equivalent to
-the right-hand side of the matrix equation (18) referenced in:
-http://mathworld.wolfram.com/CubicSpline.html
+The knots may be unevenly spaced. Earlier versions solved
+the equally-spaced system in the segment index for every
+data set, so on uneven knots they returned a different
+curve (whose slope jumped at the knots); the output for
+evenly spaced data is unchanged.
+
+Equivalent to CubicSplineEnds(d, SplineEnds{Cond: Natural})
 */
 func CubicSpline(d *BiVariateData) *CubicSplineInterpolation {
-	spline := &CubicSplineInterpolation{data: d}
+	//natural end conditions can't fail
+	spl, _ := CubicSplineEnds(d, SplineEnds{Cond: Natural})
+	return spl
+}
+
+/*
+Constructs a CubicSplineInterpolation from the data in 'd'
+closed by the end conditions in 'ends'
+
+Solves the tridiagonal system for the knot slopes
+(a cyclic tridiagonal system for Periodic splines).
+Knots need not be equally spaced.
+
+Returns ErrPeriodic if 'ends' is Periodic and the first
+and last Ys differ.
+*/
+func CubicSplineEnds(d *BiVariateData, ends SplineEnds) (*CubicSplineInterpolation, error) {
 	N := len(d.Ys)
 
-	//Edge case - len(Ys) is zero
+	//Edge case - fewer than two points
 	//Return default contructor
-	if N == 0 {
-		return spline
+	if N < 2 {
+		return &CubicSplineInterpolation{data: d}, nil
 	}
+	d.Sort()
 
-	fs := make([]float64, N)
-	fs[0] = 3 * (d.Ys[1] - d.Ys[0])
-	for i := 1; i < (N - 1); i++ {
-		fs[i] = 3 * (d.Ys[i+1] - d.Ys[i-1])
+	if ends.Cond == Periodic && math.Abs(d.Ys[N-1]-d.Ys[0]) > 1E-12*math.Max(1.0, math.Abs(d.Ys[0])) {
+		return nil, ErrPeriodic
 	}
-	fs[N-1] = 3 * (d.Ys[N-1] - d.Ys[N-2])
 
-	getCubicSplinCoeffs(fs)
-	spline.coeffs = fs
+	return linearSpline(d, ends), nil
+}
+
+/*
+Builds the spline through sorted data 'd' (at least two
+points) closed by 'ends', along with its adjoint: given
+weights 'wy' on the knot values and 'wD' on the coeffs,
+adj returns the weights on the y-values of 'd' that give
+the same linear combination, which is how
+UncertainSpline propagates errors through it
+*/
+func linearSpline(d *BiVariateData, ends SplineEnds) *CubicSplineInterpolation {
+	h, del := secants(d.Xs, d.Ys)
+	sys := newSlopeSystem(h, ends)
+	spl := hermiteSpline(d, sys.slopes(del))
+	spl.adj = func(wy []float64, wD []float64) []float64 {
+		//coeffs[i] = m[i]*h[i], and the last knot borrows the last width
+		wm := make([]float64, len(wD))
+		for i := range wm {
+			k := i
+			if k == len(h) {
+				k--
+			}
+			wm[i] = wD[i] * h[k]
+		}
+		return sys.adjoint(wy, wm)
+	}
+	return spl
+}
 
-	return spline
+/*
+Builds a CubicSplineInterpolation on sorted data 'd'
+from the first derivative 'm' at each knot
+*/
+func hermiteSpline(d *BiVariateData, m []float64) *CubicSplineInterpolation {
+	N := len(m)
+	coeffs := make([]float64, N)
+	for i := 0; i < N-1; i++ {
+		coeffs[i] = m[i] * (d.Xs[i+1] - d.Xs[i])
+	}
+	coeffs[N-1] = m[N-1] * (d.Xs[N-1] - d.Xs[N-2])
+	return &CubicSplineInterpolation{data: d, coeffs: coeffs}
+}
+
+/*
+Returns the coefficients of the cubic a + b*t + c*t^2 + d*t^3,
+with t = (x - Xs[i])/h, that describes the spline between
+knots 'i' and 'i+1', along with the segment width 'h'
+*/
+func (s *CubicSplineInterpolation) segment(i int) (a, b, c, d, h float64) {
+	xs, ys := s.data.Xs, s.data.Ys
+	h = xs[i+1] - xs[i]
+	Di, Di1 := s.coeffs[i], s.coeffs[i+1]
+
+	//coeffs[i+1] is scaled by the width of the next segment
+	if i+2 < len(xs) {
+		Di1 *= h / (xs[i+2] - xs[i+1])
+	}
+
+	a, b, c, d = hermiteCoeffs(ys[i], ys[i+1], Di, Di1)
+	return
+}
+
+/*
+Coefficients of the cubic in t on [0, 1] with values
+'y0', 'y1' and t-derivatives 'D0', 'D1' at its ends
+*/
+func hermiteCoeffs(y0 float64, y1 float64, D0 float64, D1 float64) (a, b, c, d float64) {
+	return y0, D0, 3*(y1-y0) - 2*D0 - D1, 2*(y0-y1) + D0 + D1
+}
+
+/*
+Returns a copy of 's' that uses the policy 'ext' outside
+the data endpoints; 's' itself is unchanged
+
+e.g. spl := CubicSpline(bvd).Extrapolate(ExtrapolateNaN)
+*/
+func (s *CubicSplineInterpolation) Extrapolate(ext Extrapolation) *CubicSplineInterpolation {
+	out := *s
+	out.ext = ext
+	return &out
+}
+
+//Returns the first and last x-values of the data
+func (s *CubicSplineInterpolation) Domain() (float64, float64) {
+	s.data.Sort()
+	return s.data.Xs[0], s.data.Xs[len(s.data.Xs)-1]
 }
 
 /*
@@ -87,15 +225,13 @@ func (s *CubicSplineInterpolation) F(x float64) float64 {
 	if notRat(x) {
 		return math.NaN()
 	}
+	if out, ok := extrapolate(s, s.ext, x, 0); ok {
+		return out
+	}
 
-	i, i1 := s.data.findXBounds(x)
-	t := (x - s.data.Xs[i]) / (s.data.Xs[i1] - s.data.Xs[i])
-	yi, yi1 := s.data.Ys[i], s.data.Ys[i1]
-	Di, Di1 := s.coeffs[i], s.coeffs[i+1]
-	a := yi
-	b := Di
-	c := 3*(yi1-yi) - 2*Di - Di1
-	d := 2*(yi-yi1) + Di + Di1
+	i, _ := s.data.findXBounds(x)
+	a, b, c, d, h := s.segment(i)
+	t := (x - s.data.Xs[i]) / h
 
 	return a + b*t + c*t*t + d*t*t*t
 }
@@ -103,25 +239,21 @@ func (s *CubicSplineInterpolation) F(x float64) float64 {
 /*
 First derivative of interpolated data
 evaluated at 'x'
-
 */
 func (s *CubicSplineInterpolation) DF(x float64) float64 {
 	//Edge case - x is not rational
 	if notRat(x) {
 		return math.NaN()
 	}
+	if out, ok := extrapolate(s, s.ext, x, 1); ok {
+		return out
+	}
 
-	i, i1 := s.data.findXBounds(x)
-	fin := s.data.Xs[i1]
-	start := s.data.Xs[i]
-	t := (x - start) / (fin - start)
-	yi, yi1 := s.data.Ys[i], s.data.Ys[i1]
-	Di, Di1 := s.coeffs[i], s.coeffs[i+1]
-	b := Di
-	c := 3*(yi1-yi) - 2*Di - Di1
-	d := 2*(yi-yi1) + Di + Di1
+	i, _ := s.data.findXBounds(x)
+	_, b, c, d, h := s.segment(i)
+	t := (x - s.data.Xs[i]) / h
 
-	return (b + 2*c*t + 3*d*t*t) / (fin - start)
+	return (b + 2*c*t + 3*d*t*t) / h
 }
 
 /*
@@ -132,17 +264,15 @@ func (s *CubicSplineInterpolation) DDF(x float64) float64 {
 	if notRat(x) {
 		return math.NaN()
 	}
+	if out, ok := extrapolate(s, s.ext, x, 2); ok {
+		return out
+	}
 
-	i, i1 := s.data.findXBounds(x)
-	fin := s.data.Xs[i1]
-	start := s.data.Xs[i]
-	t := (x - start) / (fin - start)
-	yi, yi1 := s.data.Ys[i], s.data.Ys[i1]
-	Di, Di1 := s.coeffs[i], s.coeffs[i+1]
-	c := 3*(yi1-yi) - 2*Di - Di1
-	d := 2*(yi-yi1) + Di + Di1
+	i, _ := s.data.findXBounds(x)
+	_, _, c, d, h := s.segment(i)
+	t := (x - s.data.Xs[i]) / h
 
-	return (2*c + 6*d*t) / ((fin - start) * (fin - start))
+	return (2*c + 6*d*t) / (h * h)
 }
 
 /*
@@ -158,103 +288,285 @@ func (s *CubicSplineInterpolation) Integral(a float64, b float64) float64 {
 	if notRat(a) || notRat(b) {
 		return math.NaN()
 	}
+	if a > b {
+		return -s.Integral(b, a)
+	}
+	if out, ok := extrapIntegral(s, s.ext, a, b); ok {
+		return out
+	}
 
-	ia, ia1 := s.data.findXBounds(a)
-	ib, ib1 := s.data.findXBounds(b)
+	ia, _ := s.data.findXBounds(a)
+	ib, _ := s.data.findXBounds(b)
 
 	//t1 - t up to nearest datapoint
-	t1 := (a - s.data.Xs[ia]) / (s.data.Xs[ia1] - s.data.Xs[ia])
+	t1 := (a - s.data.Xs[ia]) / (s.data.Xs[ia+1] - s.data.Xs[ia])
 	//t2 - t after last datapoint
-	t2 := (b - s.data.Xs[ib]) / (s.data.Xs[ib1] - s.data.Xs[ib])
-	out := 0.0
-
-	//context before first datapoint in integral range
-	for {
-		yi, yi1 := s.data.Ys[ia], s.data.Ys[ia1]
-		Di, Di1 := s.coeffs[ia], s.coeffs[ia1]
-		a := yi
-		b := Di
-		c := 3*(yi1-yi) - 2*Di - Di1
-		d := 2*(yi-yi1) + Di + Di1
+	t2 := (b - s.data.Xs[ib]) / (s.data.Xs[ib+1] - s.data.Xs[ib])
 
-		out += (s.data.Xs[ia1] - s.data.Xs[ia]) * (a + (b / 2.0) + (c / 3.0) + (d / 4.0) - a*t1 - (b*t1*t1)/2.0 - (c*t1*t1*t1)/3.0 - (d*t1*t1*t1*t1)/4.0)
-		break
+	//both bounds in the same segment
+	if ia == ib {
+		return s.segIntegral(ia, t1, t2)
 	}
 
+	//context before first datapoint in integral range
+	out := s.segIntegral(ia, t1, 1.0)
+
 	//middle contexts (within datapoint & integral range)
 	for i := ia + 1; i < ib; i++ {
-		yi, yi1 := s.data.Ys[i], s.data.Ys[i+1]
-		Di, Di1 := s.coeffs[i], s.coeffs[i+1]
-		a := yi
-		b := Di
-		c := 3*(yi1-yi) - 2*Di - Di1
-		d := 2*(yi-yi1) + Di + Di1
-
-		out += (s.data.Xs[i+1] - s.data.Xs[i]) * (a + (b / 2.0) + (c / 3.0) + (d / 4.0))
+		out += s.segIntegral(i, 0.0, 1.0)
 	}
 
 	//context after last datapoint in integral range
-	for {
-		yi, yi1 := s.data.Ys[ib], s.data.Ys[ib1]
-		Di, Di1 := s.coeffs[ib], s.coeffs[ib1]
-		a := yi
-		b := Di
-		c := 3*(yi1-yi) - 2*Di - Di1
-		d := 2*(yi-yi1) + Di + Di1
-
-		out += (a*t2 + (b*t2*t2)/2.0 + (c*t2*t2*t2)/3.0 + (d*t2*t2*t2*t2)/4.0) * (s.data.Xs[ib1] - s.data.Xs[ib])
-		break
-	}
+	out += s.segIntegral(ib, 0.0, t2)
 
 	return out
 }
 
-func makeConstVec(a float64, N int) []float64 {
-	out := make([]float64, N)
-	for i := range out {
-		x := a
-		out[i] = x
+//Integral of segment 'i' from 't1' to 't2'
+func (s *CubicSplineInterpolation) segIntegral(i int, t1 float64, t2 float64) float64 {
+	a, b, c, d, h := s.segment(i)
+	prim := func(t float64) float64 {
+		return a*t + (b*t*t)/2.0 + (c*t*t*t)/3.0 + (d*t*t*t*t)/4.0
 	}
-	return out
+	return h * (prim(t2) - prim(t1))
 }
 
 /*
-Computes the coefficients for
-cubic spline interpolation from an array of
-values by means of solving a tridiagonal matrix
-using gaussian elimination and back-substitution
+Computes the first derivative at each knot of
+a cubic spline through 'xs' and 'ys' closed by 'ends'
+
+Interior rows enforce continuity of the second derivative:
+h[i]*m[i-1] + 2*(h[i-1]+h[i])*m[i] + h[i-1]*m[i+1] = 3*(h[i]*del[i-1] + h[i-1]*del[i])
+where h[i] is the width of segment 'i' and del[i] its secant slope.
 
 See:
 http://mathworld.wolfram.com/CubicSpline.html
-Eqn (18)
+(Eqn (18) is the equally-spaced natural case)
 */
-func getCubicSplinCoeffs(x []float64) {
-	l := len(x)
+func splineSlopes(xs []float64, ys []float64, ends SplineEnds) []float64 {
+	h, del := secants(xs, ys)
+	return newSlopeSystem(h, ends).slopes(del)
+}
+
+/*
+Linear system T*m = r for the knot slopes of a cubic
+spline. T is tridiagonal, or cyclic tridiagonal over the
+knots 0...n-2 for Periodic splines (m[n-1] == m[0]); each
+row of r is a constant plus a combination of the secant
+slopes, so the slopes are linear in the y-values.
+*/
+type slopeSystem struct {
+	a, b, c []float64   //sub-, main and superdiagonal
+	r       []float64   //constant part of the right-hand side
+	terms   [][]delTerm //secant part of each row
+	h       []float64
+	cyclic  bool
+}
 
-	//subdiagonal
-	a := makeConstVec(1.0, l)
+//w*del[k] in a row of the right-hand side
+type delTerm struct {
+	k int
+	w float64
+}
+
+//slope system for the segment widths 'h' closed by 'ends'
+func newSlopeSystem(h []float64, ends SplineEnds) *slopeSystem {
+	n := len(h) + 1
+	rows := n
+	if ends.Cond == Periodic {
+		rows = n - 1
+	}
+	s := &slopeSystem{
+		a:      make([]float64, rows),
+		b:      make([]float64, rows),
+		c:      make([]float64, rows),
+		r:      make([]float64, rows),
+		terms:  make([][]delTerm, rows),
+		h:      h,
+		cyclic: ends.Cond == Periodic,
+	}
+
+	if s.cyclic {
+		for i := 0; i < rows; i++ {
+			p := (i + rows - 1) % rows
+			s.a[i] = h[i]
+			s.b[i] = 2 * (h[p] + h[i])
+			s.c[i] = h[p]
+			s.terms[i] = []delTerm{{p, 3 * h[i]}, {i, 3 * h[p]}}
+		}
+		return s
+	}
+
+	//Not-a-knot needs at least 4 points;
+	//with fewer the spline is the interpolating polynomial
+	if ends.Cond == NotAKnot && n < 4 {
+		for i := range s.b {
+			s.b[i] = 1.0
+		}
+		if n == 2 {
+			s.terms[0] = []delTerm{{0, 1}}
+			s.terms[1] = []delTerm{{0, 1}}
+			return s
+		}
+		f0, f1 := h[0]/(h[0]+h[1]), h[1]/(h[0]+h[1])
+		s.terms[0] = []delTerm{{0, 1 + f0}, {1, -f0}}
+		s.terms[1] = []delTerm{{0, 1 - f0}, {1, f0}}
+		s.terms[2] = []delTerm{{0, -f1}, {1, 1 + f1}}
+		return s
+	}
 
-	//main diag
-	b := makeConstVec(4.0, l)
-	b[0] = 2.0
-	b[l-1] = 2.0
+	for i := 1; i < n-1; i++ {
+		s.a[i] = h[i]
+		s.b[i] = 2 * (h[i-1] + h[i])
+		s.c[i] = h[i-1]
+		s.terms[i] = []delTerm{{i - 1, 3 * h[i]}, {i, 3 * h[i-1]}}
+	}
 
-	//superdiagonal
-	c := makeConstVec(1.0, l)
+	switch ends.Cond {
+	case Clamped:
+		s.b[0], s.r[0] = 1.0, ends.Left
+		s.b[n-1], s.r[n-1] = 1.0, ends.Right
+	case NotAKnot:
+		d0 := h[0] + h[1]
+		s.b[0], s.c[0] = h[1], d0
+		s.terms[0] = []delTerm{{0, (h[0] + 2*d0) * h[1] / d0}, {1, h[0] * h[0] / d0}}
+		dn := h[n-3] + h[n-2]
+		s.a[n-1], s.b[n-1] = dn, h[n-3]
+		s.terms[n-1] = []delTerm{{n - 3, h[n-2] * h[n-2] / dn}, {n - 2, (2*dn + h[n-2]) * h[n-3] / dn}}
+	default:
+		s.b[0], s.c[0] = 2.0, 1.0
+		s.terms[0] = []delTerm{{0, 3}}
+		s.a[n-1], s.b[n-1] = 1.0, 2.0
+		s.terms[n-1] = []delTerm{{n - 2, 3}}
+	}
+	return s
+}
 
-	c[0] = (c[0] / b[0])
-	x[0] = (x[0] / b[0])
+//Solves for the knot slopes given the secant slopes 'del'
+func (s *slopeSystem) slopes(del []float64) []float64 {
+	m := append([]float64{}, s.r...)
+	for i, row := range s.terms {
+		for _, t := range row {
+			m[i] += t.w * del[t.k]
+		}
+	}
+	s.solve(m, false)
+	if s.cyclic {
+		m = append(m, m[0])
+	}
+	return m
+}
+
+/*
+Returns the weights on the y-values that give
+sum(wy[i]*y[i] + wm[i]*m[i]), where 'm' are the slopes
+solved from those y-values (the transpose of the map from
+y-values to slopes, applied to 'wm', plus 'wy')
+
+For Periodic splines the last y-value repeats the first,
+so its weight is moved onto the first.
+*/
+func (s *slopeSystem) adjoint(wy []float64, wm []float64) []float64 {
+	n := len(s.h) + 1
+	z := append([]float64{}, wm[:len(s.b)]...)
+	if s.cyclic {
+		z[0] += wm[n-1]
+	}
+	s.solve(z, true)
+
+	out := append([]float64{}, wy...)
+	for i, row := range s.terms {
+		for _, t := range row {
+			w := t.w * z[i] / s.h[t.k]
+			out[t.k+1] += w
+			out[t.k] -= w
+		}
+	}
+	if s.cyclic {
+		out[0] += out[n-1]
+		out[n-1] = 0.0
+	}
+	return out
+}
+
+//Solves T*x = b (or T'*x = b if 'transpose') in-place
+func (s *slopeSystem) solve(x []float64, transpose bool) {
+	k := len(x)
+	a, c := s.a, s.c
+	if transpose {
+		a, c = make([]float64, k), make([]float64, k)
+		for i := range x {
+			a[i] = s.c[(i+k-1)%k]
+			c[i] = s.a[(i+1)%k]
+		}
+	}
+	if !s.cyclic {
+		solveTridiag(a, s.b, c, x)
+		return
+	}
+	solveCyclic(a, s.b, c, x)
+}
+
+/*
+Solves a cyclic tridiagonal system in-place by the
+Sherman-Morrison formula; as solveTridiag, with the
+corners a[0] at (0, k-1) and c[k-1] at (k-1, 0)
+*/
+func solveCyclic(a []float64, b []float64, c []float64, x []float64) {
+	k := len(x)
+	switch k {
+	case 1:
+		x[0] /= a[0] + b[0] + c[0]
+		return
+	case 2:
+		//corners fold onto the off-diagonals
+		o0, o1 := a[0]+c[0], a[1]+c[1]
+		det := b[0]*b[1] - o0*o1
+		x[0], x[1] = (x[0]*b[1]-o0*x[1])/det, (b[0]*x[1]-o1*x[0])/det
+		return
+	}
+
+	//corners: alpha at (k-1, 0), beta at (0, k-1)
+	alpha, beta := c[k-1], a[0]
+	gamma := -b[0]
+	bb := append([]float64{}, b...)
+	bb[0] -= gamma
+	bb[k-1] -= alpha * beta / gamma
+	u := make([]float64, k)
+	u[0], u[k-1] = gamma, alpha
+	solveTridiag(a, bb, c, x)
+	solveTridiag(a, bb, c, u)
+	fact := (x[0] + beta*x[k-1]/gamma) / (1.0 + u[0] + beta*u[k-1]/gamma)
+	for i := range x {
+		x[i] -= fact * u[i]
+	}
+}
+
+/*
+Solves a tridiagonal system in-place by gaussian
+elimination and back-substitution (Thomas algorithm)
+
+'a' - subdiagonal (a[0] is ignored)
+'b' - main diagonal
+'c' - superdiagonal (c[len-1] is ignored)
+'x' - right-hand side on input, solution on output
+*/
+func solveTridiag(a []float64, b []float64, c []float64, x []float64) {
+	l := len(x)
+	cp := make([]float64, l)
+
+	cp[0] = c[0] / b[0]
+	x[0] = x[0] / b[0]
 
 	//gaussian elimination
 	for i := 1; i < l; i++ {
-		m := 1.0 / (b[i] - (a[i] * c[i-1]))
-		c[i] = c[i] * m
+		m := 1.0 / (b[i] - (a[i] * cp[i-1]))
+		cp[i] = c[i] * m
 		x[i] = (x[i] - (a[i] * x[i-1])) * m
 	}
 
 	//backsubstitution
 	for i := l - 2; i >= 0; i-- {
-		x[i] = x[i] - c[i]*x[i+1]
+		x[i] = x[i] - cp[i]*x[i+1]
 	}
-
 }
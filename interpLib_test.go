@@ -20,6 +20,19 @@ func areSimilar(a float64, b float64) bool {
 	}
 }
 
+//Comparing slices element-wise to within 'tol'
+func sameSlice(a []float64, b []float64, tol float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > tol {
+			return false
+		}
+	}
+	return true
+}
+
 /*
 Arange - yields N equally-spaced elements from a to b
 
@@ -65,6 +78,31 @@ func TestCubicSpline(t *testing.T) {
 	}
 }
 
+/*
+Test CubicSpline on unevenly spaced knots
+
+- values and slopes are those of the natural spline in x
+(computed exactly in rational arithmetic), not of the
+spline in the segment index that CubicSpline used to solve
+*/
+func TestCubicSplineUneven(t *testing.T) {
+	spl := CubicSpline(MakeBiVariateData([]float64{0, 0.5, 2, 2.25, 4}, []float64{1, 3, -1, 0, 2}))
+	want := [][3]float64{
+		{0.25, 2.2686927043544691, 4.3582569391392925},
+		{1.2, 0.96739156268568038, -4.9603514132925897},
+		{2.1, -0.65756302521008403, 4.0548764960529668},
+		{3.3, 2.1406951871657753, 0.31094983447924623},
+	}
+	for _, w := range want {
+		if math.Abs(spl.F(w[0])-w[1]) > 1E-13 || math.Abs(spl.DF(w[0])-w[2]) > 1E-13 {
+			t.Error("CubicSpline is wrong on uneven knots at", w[0], "Got:", spl.F(w[0]), spl.DF(w[0]), "Expected:", w[1], w[2])
+		}
+	}
+	if math.Abs(spl.DDF(0)) > 1E-12 || math.Abs(spl.DDF(4)) > 1E-12 {
+		t.Error("CubicSpline is not natural on uneven knots. Got:", spl.DDF(0), spl.DDF(4), "Expected:", 0, 0)
+	}
+}
+
 //verify data operations
 func TestXBounds(t *testing.T) {
 	xs := Arange(0, 20, 500)
@@ -119,6 +157,72 @@ func TestSplineOuts(t *testing.T) {
 
 }
 
+/*
+Test the selectable end conditions
+
+- clamped spline with exact end slopes tracks sin() between knots
+- not-a-knot spline reproduces a cubic exactly (uneven spacing)
+- periodic spline has matching derivatives at both ends
+*/
+func TestSplineEnds(t *testing.T) {
+	xs := Arange(0, 3, 60)
+	xs = append(xs, 3.0)
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = math.Sin(x)
+	}
+	spl, _ := CubicSplineEnds(MakeBiVariateData(xs, ys), SplineEnds{Cond: Clamped, Left: 1.0, Right: math.Cos(3.0)})
+	for _, x := range []float64{0.01, 1.234, 2.987} {
+		if math.Abs(spl.F(x)-math.Sin(x)) > 1E-7 {
+			t.Error("Clamped spline inaccurate at", x, "Got:", spl.F(x), "Expected:", math.Sin(x))
+		}
+	}
+	if math.Abs(spl.DF(0)-1.0) > 1E-12 {
+		t.Error("Clamped spline does not honour left slope. Got:", spl.DF(0))
+	}
+
+	cub := func(x float64) float64 {
+		return x*x*x - 2*x*x + 0.5*x - 3
+	}
+	xs = []float64{0, 0.3, 1.1, 1.2, 2.5, 4}
+	ys = make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = cub(x)
+	}
+	spl, _ = CubicSplineEnds(MakeBiVariateData(xs, ys), SplineEnds{Cond: NotAKnot})
+	for _, x := range []float64{0.1, 0.7, 1.15, 3.3} {
+		if !areSimilar(spl.F(x), cub(x)) {
+			t.Error("Not-a-knot spline does not reproduce a cubic at", x, "Got:", spl.F(x), "Expected:", cub(x))
+		}
+	}
+	if relativeError(spl.Integral(0.3, 2.5), 2.5*2.5*2.5*2.5/4-2*2.5*2.5*2.5/3+0.25*2.5*2.5-7.5-(0.3*0.3*0.3*0.3/4-2*0.3*0.3*0.3/3+0.25*0.3*0.3-0.9)) > 1E-12 {
+		t.Error("Not-a-knot spline integral is wrong. Got:", spl.Integral(0.3, 2.5))
+	}
+
+	xs = Arange(0, 2*math.Pi, 25)
+	xs = append(xs, 2*math.Pi)
+	ys = make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = math.Sin(x)
+	}
+	ys[len(ys)-1] = ys[0]
+	spl, err := CubicSplineEnds(MakeBiVariateData(xs, ys), SplineEnds{Cond: Periodic})
+	if err != nil {
+		t.Fatal("Periodic spline rejected periodic data. Got:", err)
+	}
+	if math.Abs(spl.DF(0)-spl.DF(2*math.Pi)) > 1E-12 || math.Abs(spl.DDF(0)-spl.DDF(2*math.Pi)) > 1E-12 {
+		t.Error("Periodic spline derivatives don't wrap around")
+	}
+	if math.Abs(spl.DF(0)-1.0) > 1E-3 {
+		t.Error("Periodic spline slope at 0 is off. Got:", spl.DF(0))
+	}
+
+	ys[len(ys)-1] = 1.0
+	if _, err := CubicSplineEnds(MakeBiVariateData(xs, ys), SplineEnds{Cond: Periodic}); err != ErrPeriodic {
+		t.Error("Periodic spline accepted non-periodic data. Got:", err)
+	}
+}
+
 func BenchmarkSpline(b *testing.B) {
 	xs := Arange(0, 10, 1000)
 	ys := Arange(0, 10, 1000)
@@ -0,0 +1,128 @@
+package vec
+
+import (
+	"errors"
+	"math"
+)
+
+/*
+Interpolator - common surface of the one-dimensional
+interpolations in this package. Swapping one scheme for
+another only changes the constructor call.
+
+Every interpolation takes its Extrapolation the same way,
+from its Extrapolate() method, which returns a new
+interpolation with that policy:
+
+	lin := LinearInterp(bvd).Extrapolate(ExtrapolateNaN)
+*/
+type Interpolator interface {
+	F(x float64) float64
+	DF(x float64) float64
+	DDF(x float64) float64
+	Integral(a float64, b float64) float64
+	Domain() (float64, float64)
+}
+
+//Extrapolation - policy for evaluating an Interpolator outside its Domain()
+type Extrapolation int
+
+const (
+	//ExtrapolatePoly - continue the polynomial of the end segment (the default)
+	ExtrapolatePoly Extrapolation = iota
+	//ExtrapolateNaN - return NaN
+	ExtrapolateNaN
+	//ExtrapolateClamp - hold the value at the nearest edge (derivatives are zero)
+	ExtrapolateClamp
+	//ExtrapolateLinear - continue along the tangent at the nearest edge
+	ExtrapolateLinear
+	//ExtrapolateError - panic with ErrDomain
+	ExtrapolateError
+)
+
+//ErrDomain - evaluation outside the domain of an interpolation
+var ErrDomain = errors.New("vec: evaluation outside interpolation domain")
+
+//ErrLength - input slices of mismatched lengths
+var ErrLength = errors.New("vec: mismatched lengths")
+
+//ErrParam - a parameter (window, degree, penalty, ...) out of range
+var ErrParam = errors.New("vec: parameter out of range")
+
+//ErrUnordered - x-values that are not strictly increasing
+var ErrUnordered = errors.New("vec: x-values not in increasing order")
+
+/*
+Applies the extrapolation policy 'ext' to the 'deriv'-th
+derivative (0, 1, or 2) of 'it' at 'x'
+
+Returns ok = false if 'x' is inside the domain or the policy
+is ExtrapolatePoly, in which case the caller evaluates
+its end segment as usual.
+*/
+func extrapolate(it Interpolator, ext Extrapolation, x float64, deriv int) (out float64, ok bool) {
+	lo, hi := it.Domain()
+	if ext == ExtrapolatePoly || (x >= lo && x <= hi) {
+		return 0.0, false
+	}
+	edge := lo
+	if x > hi {
+		edge = hi
+	}
+
+	switch ext {
+	case ExtrapolateError:
+		panic(ErrDomain)
+	case ExtrapolateClamp:
+		if deriv == 0 {
+			return it.F(edge), true
+		}
+		return 0.0, true
+	case ExtrapolateLinear:
+		switch deriv {
+		case 0:
+			return it.F(edge) + it.DF(edge)*(x-edge), true
+		case 1:
+			return it.DF(edge), true
+		}
+		return 0.0, true
+	}
+	return math.NaN(), true
+}
+
+/*
+Applies the extrapolation policy 'ext' to the integral
+of 'it' from 'a' to 'b' (a <= b)
+
+Returns ok = false under the same conditions as extrapolate()
+*/
+func extrapIntegral(it Interpolator, ext Extrapolation, a float64, b float64) (out float64, ok bool) {
+	lo, hi := it.Domain()
+	if ext == ExtrapolatePoly || (a >= lo && b <= hi) {
+		return 0.0, false
+	}
+
+	//part outside the domain, from 'x1' to 'x2' beyond 'edge'
+	tail := func(edge float64, x1 float64, x2 float64) float64 {
+		switch ext {
+		case ExtrapolateError:
+			panic(ErrDomain)
+		case ExtrapolateClamp:
+			return it.F(edge) * (x2 - x1)
+		case ExtrapolateLinear:
+			return it.F(edge)*(x2-x1) + it.DF(edge)*((x2-edge)*(x2-edge)-(x1-edge)*(x1-edge))/2.0
+		}
+		return math.NaN()
+	}
+
+	if a < lo {
+		out += tail(lo, a, math.Min(b, lo))
+	}
+	if ca, cb := math.Max(a, lo), math.Min(b, hi); ca < cb {
+		out += it.Integral(ca, cb)
+	}
+	if b > hi {
+		out += tail(hi, math.Max(a, hi), b)
+	}
+	return out, true
+}
@@ -0,0 +1,130 @@
+package vec
+
+import "testing"
+import "math"
+
+//all of these must be swappable at call sites
+var interpolators = []func(*BiVariateData) Interpolator{
+	func(d *BiVariateData) Interpolator { return CubicSpline(d) },
+	func(d *BiVariateData) Interpolator { return LinearInterp(d) },
+	func(d *BiVariateData) Interpolator { return NearestInterp(d) },
+	func(d *BiVariateData) Interpolator { return StepInterp(d) },
+}
+
+/*
+Test Linear, Nearest and Step interpolation
+
+- pass through the data
+- integrate exactly over the data range
+*/
+func TestSimpleInterps(t *testing.T) {
+	xs := []float64{0, 1, 3, 4}
+	ys := []float64{1, 3, -1, 0}
+	bvd := MakeBiVariateData(xs, ys)
+
+	for k, mk := range interpolators {
+		it := mk(bvd)
+		for i, x := range xs {
+			if it.F(x) != ys[i] {
+				t.Error("Interpolator", k, "does not conform to yi = f(xi) rule at", x, "Got:", it.F(x))
+			}
+		}
+		lo, hi := it.Domain()
+		if lo != 0 || hi != 4 {
+			t.Error("Interpolator", k, "has the wrong domain:", lo, hi)
+		}
+	}
+
+	lin := LinearInterp(bvd)
+	if lin.F(2.0) != 1.0 || lin.DF(2.0) != -2.0 {
+		t.Error("LinearInterp is wrong at 2.0. Got:", lin.F(2.0), lin.DF(2.0))
+	}
+	if lin.Integral(0, 4) != 2.0+2.0-0.5 {
+		t.Error("LinearInterp integral is wrong. Got:", lin.Integral(0, 4))
+	}
+	if NearestInterp(bvd).Integral(0, 4) != 0.5+1.5+3-1-0.5 {
+		t.Error("NearestInterp integral is wrong. Got:", NearestInterp(bvd).Integral(0, 4))
+	}
+	if StepInterp(bvd).Integral(-1, 5) != 1+1+6-1+0 {
+		t.Error("StepInterp integral is wrong. Got:", StepInterp(bvd).Integral(-1, 5))
+	}
+}
+
+/*
+Test each extrapolation policy
+
+EDGE CASES:
+- integrals straddling the domain
+- ExtrapolateError panics with ErrDomain
+*/
+func TestExtrapolation(t *testing.T) {
+	xs := Arange(0, 1, 10)
+	xs = append(xs, 1.0)
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = x * x
+	}
+	bvd := MakeBiVariateData(xs, ys)
+
+	if !math.IsNaN(LinearInterp(bvd).Extrapolate(ExtrapolateNaN).F(1.5)) {
+		t.Error("ExtrapolateNaN did not yield NaN")
+	}
+	if LinearInterp(bvd).Extrapolate(ExtrapolateNaN).F(0.5) != 0.25 {
+		t.Error("ExtrapolateNaN changed values inside the domain")
+	}
+
+	spl := CubicSpline(bvd).Extrapolate(ExtrapolateClamp)
+	if spl.F(3.0) != 1.0 || spl.DF(3.0) != 0.0 || spl.F(-2.0) != 0.0 {
+		t.Error("ExtrapolateClamp did not hold the end values")
+	}
+	if !areSimilar(spl.Integral(0.5, 3.0), spl.Integral(0.5, 1.0)+2.0) {
+		t.Error("ExtrapolateClamp integral is wrong. Got:", spl.Integral(0.5, 3.0))
+	}
+
+	spl = spl.Extrapolate(ExtrapolateLinear)
+	slope := spl.DF(1.0)
+	if !areSimilar(spl.F(2.0), 1.0+slope) || spl.DDF(2.0) != 0.0 {
+		t.Error("ExtrapolateLinear did not follow the end tangent. Got:", spl.F(2.0))
+	}
+	if !areSimilar(spl.Integral(1.0, 2.0), 1.0+slope/2.0) {
+		t.Error("ExtrapolateLinear integral is wrong. Got:", spl.Integral(1.0, 2.0))
+	}
+
+	defer func() {
+		if r := recover(); r != ErrDomain {
+			t.Error("ExtrapolateError did not panic with ErrDomain. Got:", r)
+		}
+	}()
+	StepInterp(bvd).Extrapolate(ExtrapolateError).F(-0.1)
+}
+
+/*
+Test Extrapolate() and Domain()
+- Extrapolate returns a copy and leaves the original alone
+- Domain stays current after an out-of-order Append
+*/
+func TestExtrapolateCopy(t *testing.T) {
+	bvd := MakeBiVariateData([]float64{0, 1, 2}, []float64{0, 1, 4})
+	lin := LinearInterp(bvd)
+	nan := lin.Extrapolate(ExtrapolateNaN)
+	if !math.IsNaN(nan.F(3.0)) || lin.F(3.0) != 7.0 {
+		t.Error("Extrapolate changed the original. Got:", lin.F(3.0), "Expected:", 7.0)
+	}
+
+	bvd.Append(-1.0, 1.0)
+	for _, it := range []Interpolator{lin, NearestInterp(bvd), StepInterp(bvd)} {
+		if a, b := it.Domain(); a != -1.0 || b != 2.0 {
+			t.Error("Domain went stale after Append. Got:", a, b, "Expected:", -1.0, 2.0)
+		}
+	}
+
+	s := StreamingSpline(StreamMonotone, 0)
+	for i := 0; i < 5; i++ {
+		s.Push(float64(i), float64(i*i))
+	}
+	c := s.Extrapolate(ExtrapolateClamp)
+	s.Push(5.0, 25.0)
+	if c.Len() != 5 || !areSimilar(c.F(6.0), 16.0) {
+		t.Error("Streaming Extrapolate did not copy. Got:", c.Len(), c.F(6.0), "Expected:", 5, 16.0)
+	}
+}
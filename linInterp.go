@@ -0,0 +1,286 @@
+package vec
+
+import "math"
+
+/*
+Piecewise-linear and piecewise-constant interpolations
+
+LinearInterpolation  - straight lines between knots
+NearestInterpolation - value of the nearest knot
+StepInterpolation    - value of the last knot at or below x
+
+Piecewise-constant interpolations have zero derivatives
+(the jumps at the breakpoints are ignored).
+*/
+
+//LinearInterpolation type def
+type LinearInterpolation struct {
+	data *BiVariateData
+	ext  Extrapolation
+}
+
+//NearestInterpolation type def
+type NearestInterpolation struct {
+	data *BiVariateData
+	ext  Extrapolation
+}
+
+//StepInterpolation type def
+type StepInterpolation struct {
+	data *BiVariateData
+	ext  Extrapolation
+}
+
+//Constructs a LinearInterpolation of 'd'
+func LinearInterp(d *BiVariateData) *LinearInterpolation {
+	d.Sort()
+	return &LinearInterpolation{data: d}
+}
+
+/*
+Returns a copy of 'l' that uses the policy 'ext' outside
+the data endpoints; 'l' itself is unchanged
+*/
+func (l *LinearInterpolation) Extrapolate(ext Extrapolation) *LinearInterpolation {
+	out := *l
+	out.ext = ext
+	return &out
+}
+
+//Constructs a NearestInterpolation of 'd'
+func NearestInterp(d *BiVariateData) *NearestInterpolation {
+	d.Sort()
+	return &NearestInterpolation{data: d}
+}
+
+/*
+Returns a copy of 'n' that uses the policy 'ext' outside
+the data endpoints; 'n' itself is unchanged
+*/
+func (n *NearestInterpolation) Extrapolate(ext Extrapolation) *NearestInterpolation {
+	out := *n
+	out.ext = ext
+	return &out
+}
+
+//Constructs a StepInterpolation of 'd'
+func StepInterp(d *BiVariateData) *StepInterpolation {
+	d.Sort()
+	return &StepInterpolation{data: d}
+}
+
+/*
+Returns a copy of 's' that uses the policy 'ext' outside
+the data endpoints; 's' itself is unchanged
+*/
+func (s *StepInterpolation) Extrapolate(ext Extrapolation) *StepInterpolation {
+	out := *s
+	out.ext = ext
+	return &out
+}
+
+/*
+Sums 'seg(i, x1, x2)' over the knot intervals covering
+'a' to 'b' (a <= b). The first and last intervals extend
+past the data endpoints.
+*/
+func segSum(d *BiVariateData, a float64, b float64, seg func(int, float64, float64) float64) float64 {
+	ia, _ := d.findXBounds(a)
+	ib, _ := d.findXBounds(b)
+	if ia == ib {
+		return seg(ia, a, b)
+	}
+	out := seg(ia, a, d.Xs[ia+1])
+	for i := ia + 1; i < ib; i++ {
+		out += seg(i, d.Xs[i], d.Xs[i+1])
+	}
+	out += seg(ib, d.Xs[ib], b)
+	return out
+}
+
+//Returns the first and last x-values of the data
+func (l *LinearInterpolation) Domain() (float64, float64) {
+	l.data.Sort()
+	return l.data.Xs[0], l.data.Xs[len(l.data.Xs)-1]
+}
+
+//slope of segment 'i'
+func (l *LinearInterpolation) slope(i int) float64 {
+	return (l.data.Ys[i+1] - l.data.Ys[i]) / (l.data.Xs[i+1] - l.data.Xs[i])
+}
+
+//Returns the interpolated value at 'x'
+func (l *LinearInterpolation) F(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(l, l.ext, x, 0); ok {
+		return out
+	}
+	i, _ := l.data.findXBounds(x)
+	return l.data.Ys[i] + l.slope(i)*(x-l.data.Xs[i])
+}
+
+//First derivative at 'x'
+func (l *LinearInterpolation) DF(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(l, l.ext, x, 1); ok {
+		return out
+	}
+	i, _ := l.data.findXBounds(x)
+	return l.slope(i)
+}
+
+//Second derivative at 'x' (always zero inside the domain)
+func (l *LinearInterpolation) DDF(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(l, l.ext, x, 2); ok {
+		return out
+	}
+	return 0.0
+}
+
+//Definite integral from 'a' to 'b'
+func (l *LinearInterpolation) Integral(a float64, b float64) float64 {
+	if notRat(a) || notRat(b) {
+		return math.NaN()
+	}
+	if a > b {
+		return -l.Integral(b, a)
+	}
+	if out, ok := extrapIntegral(l, l.ext, a, b); ok {
+		return out
+	}
+	return segSum(l.data, a, b, func(i int, x1 float64, x2 float64) float64 {
+		m := l.slope(i)
+		y1 := l.data.Ys[i] + m*(x1-l.data.Xs[i])
+		y2 := l.data.Ys[i] + m*(x2-l.data.Xs[i])
+		return (x2 - x1) * (y1 + y2) / 2.0
+	})
+}
+
+//Returns the first and last x-values of the data
+func (n *NearestInterpolation) Domain() (float64, float64) {
+	n.data.Sort()
+	return n.data.Xs[0], n.data.Xs[len(n.data.Xs)-1]
+}
+
+//Returns the value of the knot nearest to 'x' (ties go to the lower knot)
+func (n *NearestInterpolation) F(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(n, n.ext, x, 0); ok {
+		return out
+	}
+	i, i1 := n.data.findXBounds(x)
+	if x-n.data.Xs[i] <= n.data.Xs[i1]-x {
+		return n.data.Ys[i]
+	}
+	return n.data.Ys[i1]
+}
+
+//First derivative at 'x' (zero away from the breakpoints)
+func (n *NearestInterpolation) DF(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(n, n.ext, x, 1); ok {
+		return out
+	}
+	return 0.0
+}
+
+//Second derivative at 'x' (zero away from the breakpoints)
+func (n *NearestInterpolation) DDF(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(n, n.ext, x, 2); ok {
+		return out
+	}
+	return 0.0
+}
+
+//Definite integral from 'a' to 'b'
+func (n *NearestInterpolation) Integral(a float64, b float64) float64 {
+	if notRat(a) || notRat(b) {
+		return math.NaN()
+	}
+	if a > b {
+		return -n.Integral(b, a)
+	}
+	if out, ok := extrapIntegral(n, n.ext, a, b); ok {
+		return out
+	}
+	return segSum(n.data, a, b, func(i int, x1 float64, x2 float64) float64 {
+		//breakpoint halfway between the knots
+		mid := math.Max(x1, math.Min(x2, (n.data.Xs[i]+n.data.Xs[i+1])/2.0))
+		return n.data.Ys[i]*(mid-x1) + n.data.Ys[i+1]*(x2-mid)
+	})
+}
+
+//Returns the first and last x-values of the data
+func (s *StepInterpolation) Domain() (float64, float64) {
+	s.data.Sort()
+	return s.data.Xs[0], s.data.Xs[len(s.data.Xs)-1]
+}
+
+//Returns the value of the last knot at or below 'x'
+func (s *StepInterpolation) F(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(s, s.ext, x, 0); ok {
+		return out
+	}
+	i, i1 := s.data.findXBounds(x)
+	if x >= s.data.Xs[i1] {
+		return s.data.Ys[i1]
+	}
+	return s.data.Ys[i]
+}
+
+//First derivative at 'x' (zero away from the breakpoints)
+func (s *StepInterpolation) DF(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(s, s.ext, x, 1); ok {
+		return out
+	}
+	return 0.0
+}
+
+//Second derivative at 'x' (zero away from the breakpoints)
+func (s *StepInterpolation) DDF(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(s, s.ext, x, 2); ok {
+		return out
+	}
+	return 0.0
+}
+
+//Definite integral from 'a' to 'b'
+func (s *StepInterpolation) Integral(a float64, b float64) float64 {
+	if notRat(a) || notRat(b) {
+		return math.NaN()
+	}
+	if a > b {
+		return -s.Integral(b, a)
+	}
+	if out, ok := extrapIntegral(s, s.ext, a, b); ok {
+		return out
+	}
+	return segSum(s.data, a, b, func(i int, x1 float64, x2 float64) float64 {
+		//the last segment hands over to the last knot at its end
+		end := math.Max(x1, math.Min(x2, s.data.Xs[i+1]))
+		return s.data.Ys[i]*(end-x1) + s.data.Ys[i+1]*(x2-end)
+	})
+}
@@ -0,0 +1,167 @@
+package vec
+
+import (
+	"errors"
+	"math"
+)
+
+/*
+Small dense linear algebra used internally by the
+fitting routines
+*/
+
+//ErrSingular - a linear system could not be solved
+var ErrSingular = errors.New("vec: singular matrix")
+
+/*
+Solves A*x = b by LU decomposition with partial pivoting
+
+'A' and 'b' are overwritten; the solution is
+returned in 'b'.
+*/
+func solveDense(A [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+
+	//scale for the singularity test
+	norm := 0.0
+	for _, row := range A {
+		for _, a := range row {
+			norm = math.Max(norm, math.Abs(a))
+		}
+	}
+
+	for k := 0; k < n; k++ {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(A[i][k]) > math.Abs(A[p][k]) {
+				p = i
+			}
+		}
+		if math.Abs(A[p][k]) <= 1E-14*norm {
+			return nil, ErrSingular
+		}
+		A[k], A[p] = A[p], A[k]
+		b[k], b[p] = b[p], b[k]
+
+		for i := k + 1; i < n; i++ {
+			m := A[i][k] / A[k][k]
+			if m == 0 {
+				continue
+			}
+			for j := k + 1; j < n; j++ {
+				A[i][j] -= m * A[k][j]
+			}
+			b[i] -= m * b[k]
+		}
+	}
+
+	//backsubstitution
+	for i := n - 1; i >= 0; i-- {
+		s := b[i]
+		for j := i + 1; j < n; j++ {
+			s -= A[i][j] * b[j]
+		}
+		b[i] = s / A[i][i]
+	}
+	return b, nil
+}
+
+//n x m matrix of zeros
+func zeros(n int, m int) [][]float64 {
+	out := make([][]float64, n)
+	for i := range out {
+		out[i] = make([]float64, m)
+	}
+	return out
+}
+
+/*
+LDL^T factorization of a symmetric positive-definite
+pentadiagonal matrix (half-bandwidth 2)
+
+l1[i] = L[i+1][i], l2[i] = L[i+2][i]
+*/
+type pentaLDL struct {
+	d, l1, l2 []float64
+}
+
+/*
+Factors the matrix with main diagonal 'd0', first
+superdiagonal 'd1' (d1[i] = M[i][i+1]) and second
+superdiagonal 'd2' (d2[i] = M[i][i+2])
+*/
+func factorPenta(d0 []float64, d1 []float64, d2 []float64) *pentaLDL {
+	n := len(d0)
+	f := &pentaLDL{make([]float64, n), make([]float64, n), make([]float64, n)}
+	for i := 0; i < n; i++ {
+		di := d0[i]
+		if i >= 1 {
+			di -= f.l1[i-1] * f.l1[i-1] * f.d[i-1]
+		}
+		if i >= 2 {
+			di -= f.l2[i-2] * f.l2[i-2] * f.d[i-2]
+		}
+		f.d[i] = di
+		if i+1 < n {
+			l := d1[i]
+			if i >= 1 {
+				l -= f.l2[i-1] * f.l1[i-1] * f.d[i-1]
+			}
+			f.l1[i] = l / di
+		}
+		if i+2 < n {
+			f.l2[i] = d2[i] / di
+		}
+	}
+	return f
+}
+
+//Solves M*x = b in-place
+func (f *pentaLDL) solve(b []float64) {
+	n := len(b)
+	for i := 1; i < n; i++ {
+		b[i] -= f.l1[i-1] * b[i-1]
+		if i >= 2 {
+			b[i] -= f.l2[i-2] * b[i-2]
+		}
+	}
+	for i := range b {
+		b[i] /= f.d[i]
+	}
+	for i := n - 2; i >= 0; i-- {
+		b[i] -= f.l1[i] * b[i+1]
+		if i+2 < n {
+			b[i] -= f.l2[i] * b[i+2]
+		}
+	}
+}
+
+/*
+Returns the central band of M^-1 (s0 diagonal, s1 and s2
+first and second superdiagonals) in O(n), following
+Hutchinson & de Hoog, Numer. Math. 47, 99-106 (1985)
+*/
+func (f *pentaLDL) inverseBand() (s0 []float64, s1 []float64, s2 []float64) {
+	n := len(f.d)
+	s0, s1, s2 = make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		//S[i][i+2], S[i][i+1], S[i][i]
+		if i+2 < n {
+			s2[i] = -f.l1[i]*s1[i+1] - f.l2[i]*s0[i+2]
+		}
+		if i+1 < n {
+			s1[i] = -f.l1[i] * s0[i+1]
+			if i+2 < n {
+				s1[i] -= f.l2[i] * s1[i+1]
+			}
+		}
+		s0[i] = 1.0 / f.d[i]
+		if i+1 < n {
+			s0[i] -= f.l1[i] * s1[i]
+		}
+		if i+2 < n {
+			s0[i] -= f.l2[i] * s2[i]
+		}
+	}
+	return
+}
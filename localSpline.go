@@ -0,0 +1,231 @@
+package vec
+
+import "math"
+
+/*
+Locally-determined cubic splines
+
+Each of these splines is a piecewise cubic Hermite
+interpolant: the slope at every knot is computed from
+a few neighbouring points only, so a single bad point
+cannot ring across the whole curve. They share the
+CubicSplineInterpolation type (and therefore F, DF,
+DDF, and Integral) with the global cubic splines.
+*/
+
+/*
+Constructs a monotone piecewise cubic Hermite
+interpolation (PCHIP) of the data in 'd'
+
+Knot slopes follow Fritsch & Carlson, using the
+weighted harmonic mean of Fritsch & Butland at
+interior knots. The interpolation is monotone on
+every interval where the data is monotone, and has
+a local extremum wherever the data does.
+
+*Note: the interpolation is only once continuously
+differentiable (DDF jumps at the knots)
+*/
+func MonotoneSpline(d *BiVariateData) *CubicSplineInterpolation {
+	return localSpline(d, pchipSlopes)
+}
+
+/*
+Constructs an Akima spline of the data in 'd'
+
+The slope at each knot is a weighted average of the
+neighbouring secants, weighted by how much the secants
+on the far side change. Outliers only affect the two
+segments on either side of them.
+
+See:
+H. Akima, J. ACM 17(4), 589-602 (1970)
+*/
+func AkimaSpline(d *BiVariateData) *CubicSplineInterpolation {
+	return localSpline(d, func(xs []float64, ys []float64) []float64 {
+		return akimaSlopes(xs, ys, false)
+	})
+}
+
+/*
+Constructs a modified Akima ("makima") spline of the data in 'd'
+
+Adds the average of each pair of secants to the Akima
+weights, which removes overshoot on flat regions and
+avoids the spurious wiggles of AkimaSpline where the
+data has equal secants on one side.
+*/
+func ModifiedAkimaSpline(d *BiVariateData) *CubicSplineInterpolation {
+	return localSpline(d, func(xs []float64, ys []float64) []float64 {
+		return akimaSlopes(xs, ys, true)
+	})
+}
+
+/*
+Constructs a Steffen spline of the data in 'd'
+
+Monotone between every pair of knots, with local extrema
+only at the knots; slopes are limited so the curve never
+overshoots a neighbouring data point.
+
+See:
+M. Steffen, Astron. Astrophys. 239, 443-450 (1990)
+*/
+func SteffenSpline(d *BiVariateData) *CubicSplineInterpolation {
+	return localSpline(d, steffenSlopes)
+}
+
+//Builds a CubicSplineInterpolation from the knot slopes computed by 'slopes'
+func localSpline(d *BiVariateData, slopes func([]float64, []float64) []float64) *CubicSplineInterpolation {
+	N := len(d.Ys)
+
+	//Edge case - fewer than two points
+	if N < 2 {
+		return &CubicSplineInterpolation{data: d}
+	}
+	d.Sort()
+
+	return hermiteSpline(d, slopes(d.Xs, d.Ys))
+}
+
+//widths and secant slopes of each segment
+func secants(xs []float64, ys []float64) (h []float64, del []float64) {
+	h = make([]float64, len(xs)-1)
+	del = make([]float64, len(xs)-1)
+	for i := range h {
+		h[i] = xs[i+1] - xs[i]
+		del[i] = (ys[i+1] - ys[i]) / h[i]
+	}
+	return
+}
+
+//PCHIP knot slopes
+func pchipSlopes(xs []float64, ys []float64) []float64 {
+	n := len(xs)
+	h, del := secants(xs, ys)
+	m := make([]float64, n)
+
+	if n == 2 {
+		m[0], m[1] = del[0], del[0]
+		return m
+	}
+
+	for i := 1; i < n-1; i++ {
+		if del[i-1]*del[i] <= 0 {
+			continue
+		}
+		w1 := 2*h[i] + h[i-1]
+		w2 := h[i] + 2*h[i-1]
+		m[i] = (w1 + w2) / (w1/del[i-1] + w2/del[i])
+	}
+
+	m[0] = pchipEnd(h[0], h[1], del[0], del[1])
+	m[n-1] = pchipEnd(h[n-2], h[n-3], del[n-2], del[n-3])
+	return m
+}
+
+/*
+Shape-preserving three-point estimate of the slope
+at an endpoint. 'h0' and 'del0' belong to the end
+segment, 'h1' and 'del1' to its neighbour.
+*/
+func pchipEnd(h0 float64, h1 float64, del0 float64, del1 float64) float64 {
+	m := ((2*h0+h1)*del0 - h0*del1) / (h0 + h1)
+	if math.Signbit(m) != math.Signbit(del0) || m == 0 || del0 == 0 {
+		return 0.0
+	}
+	if opp(del0, del1) && math.Abs(m) > math.Abs(3*del0) {
+		return 3 * del0
+	}
+	return m
+}
+
+/*
+Akima knot slopes. The secants are extended by two
+on either side by linear extrapolation.
+
+'modified' selects the makima weights.
+*/
+func akimaSlopes(xs []float64, ys []float64, modified bool) []float64 {
+	n := len(xs)
+	_, del := secants(xs, ys)
+	m := make([]float64, n)
+
+	if n == 2 {
+		m[0], m[1] = del[0], del[0]
+		return m
+	}
+
+	//ext[i+2] is the secant of segment i
+	ext := make([]float64, n+3)
+	copy(ext[2:], del)
+	ext[1] = 2*ext[2] - ext[3]
+	ext[0] = 2*ext[1] - ext[2]
+	ext[n+1] = 2*ext[n] - ext[n-1]
+	ext[n+2] = 2*ext[n+1] - ext[n]
+
+	for i := range m {
+		//secants i-2, i-1, i, i+1
+		d0, d1, d2, d3 := ext[i], ext[i+1], ext[i+2], ext[i+3]
+		w1 := math.Abs(d3 - d2)
+		w2 := math.Abs(d1 - d0)
+		if modified {
+			w1 += math.Abs(d3+d2) / 2.0
+			w2 += math.Abs(d1+d0) / 2.0
+		}
+		if w1+w2 == 0 {
+			m[i] = (d1 + d2) / 2.0
+		} else {
+			m[i] = (w1*d1 + w2*d2) / (w1 + w2)
+		}
+	}
+	return m
+}
+
+//Steffen knot slopes
+func steffenSlopes(xs []float64, ys []float64) []float64 {
+	n := len(xs)
+	h, del := secants(xs, ys)
+	m := make([]float64, n)
+
+	if n == 2 {
+		m[0], m[1] = del[0], del[0]
+		return m
+	}
+
+	for i := 1; i < n-1; i++ {
+		p := (del[i-1]*h[i] + del[i]*h[i-1]) / (h[i-1] + h[i])
+		lim := math.Min(math.Abs(del[i-1]), math.Min(math.Abs(del[i]), math.Abs(p)/2.0))
+		m[i] = (sign(del[i-1]) + sign(del[i])) * lim
+	}
+
+	m[0] = steffenEnd(h[0], h[1], del[0], del[1])
+	m[n-1] = steffenEnd(h[n-2], h[n-3], del[n-2], del[n-3])
+	return m
+}
+
+/*
+Steffen slope at an endpoint. 'h0' and 'del0' belong to
+the end segment, 'h1' and 'del1' to its neighbour.
+*/
+func steffenEnd(h0 float64, h1 float64, del0 float64, del1 float64) float64 {
+	p := del0*(1.0+h0/(h0+h1)) - del1*h0/(h0+h1)
+	if p*del0 <= 0 {
+		return 0.0
+	}
+	if math.Abs(p) > 2*math.Abs(del0) {
+		return 2 * del0
+	}
+	return p
+}
+
+//-1, 0, or 1
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1.0
+	case x < 0:
+		return -1.0
+	}
+	return 0.0
+}
@@ -0,0 +1,96 @@
+package vec
+
+import "testing"
+import "math"
+
+//monotone data with a sharp step
+func stepData() *BiVariateData {
+	xs := []float64{0, 1, 2, 3, 3.5, 4, 5, 6, 7, 8}
+	ys := []float64{0, 0.01, 0.02, 0.05, 0.5, 0.95, 0.98, 0.99, 1.0, 1.0}
+	return MakeBiVariateData(xs, ys)
+}
+
+/*
+Test PCHIP interpolation
+
+- passes through the data
+- never decreases on monotone data
+- stays within the range of the data (no overshoot)
+*/
+func TestMonotoneSpline(t *testing.T) {
+	bvd := stepData()
+	spl := MonotoneSpline(bvd)
+
+	for i, x := range bvd.Xs {
+		if math.Abs(spl.F(x)-bvd.Ys[i]) > 1E-15 {
+			t.Error("MonotoneSpline does not conform to yi = f(xi) rule at", x)
+		}
+	}
+
+	prev := spl.F(0)
+	for _, x := range Arange(0, 8, 8000) {
+		y := spl.F(x)
+		if y < prev-1E-15 {
+			t.Error("MonotoneSpline is not monotone at", x)
+			break
+		}
+		if y < 0 || y > 1.0 {
+			t.Error("MonotoneSpline overshoots at", x, "Got:", y)
+			break
+		}
+		prev = y
+	}
+
+	//flat end segment stays flat
+	if spl.DF(7.5) != 0.0 {
+		t.Error("MonotoneSpline is not flat on flat data. Got:", spl.DF(7.5))
+	}
+}
+
+/*
+Test Akima, modified Akima and Steffen splines
+
+- a single outlier on a straight line only disturbs
+  the nearby segments
+- Steffen splines don't overshoot step data
+*/
+func TestLocalSplines(t *testing.T) {
+	line := func(x float64) float64 {
+		return 0.5*x - 1.0
+	}
+	xs := Arange(0, 20, 20)
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = line(x)
+	}
+	ys[10] += 5.0
+	bvd := MakeBiVariateData(xs, ys)
+
+	splines := map[string]*CubicSplineInterpolation{
+		"Akima":         AkimaSpline(bvd),
+		"ModifiedAkima": ModifiedAkimaSpline(bvd),
+		"Steffen":       SteffenSpline(bvd),
+	}
+	for name, spl := range splines {
+		for _, x := range []float64{0.5, 3.3, 6.9, 13.1, 18.7} {
+			if math.Abs(spl.F(x)-line(x)) > 1E-12 {
+				t.Error(name, "spline propagated the outlier to", x, "Got:", spl.F(x), "Expected:", line(x))
+			}
+		}
+		if math.Abs(spl.F(10.0)-ys[10]) > 1E-12 {
+			t.Error(name, "spline does not conform to yi = f(xi) rule")
+		}
+		if relativeError(spl.Integral(0, 7), line(0)*7+0.25*49) > 1E-12 {
+			t.Error(name, "spline integral is wrong. Got:", spl.Integral(0, 7))
+		}
+	}
+
+	stp := stepData()
+	spl := SteffenSpline(stp)
+	for _, x := range Arange(0, 8, 8000) {
+		if y := spl.F(x); y < 0 || y > 1.0 {
+			t.Error("SteffenSpline overshoots at", x, "Got:", y)
+			break
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package vec
+
+import (
+	"github.com/philhofer/vec/pool"
+	"math"
+	"runtime"
+)
+
+/*
+N-dimensional interpolation on regular (rectilinear) grids
+
+The interpolant is a tensor product of one-dimensional
+interpolations along each axis: piecewise-linear for
+NdLinear, and cubic Hermite with the finite-difference
+slopes used by Bicubic for NdCubic. Points outside the
+grid are extrapolated along the edge cells.
+*/
+
+/*
+NdGrid - values on the grid spanned by 'Axes'
+
+Values are stored row-major (the last axis varies fastest),
+so len(Values) is the product of the axis lengths.
+Every axis must be ascending with at least two knots.
+*/
+type NdGrid struct {
+	Axes   [][]float64
+	Values []float64
+}
+
+//NdInterpolation type def
+type NdInterpolation struct {
+	grid    *NdGrid
+	strides []int
+	cubic   bool
+}
+
+/*
+Constructs a multilinear interpolation of 'g'
+
+Returns ErrLength if 'g' has no axes, an axis with fewer
+than two knots, or the wrong number of values, and
+ErrNotFinite if an axis holds NaN or Inf, and ErrUnordered
+if an axis is not strictly ascending.
+*/
+func NdLinear(g *NdGrid) (*NdInterpolation, error) {
+	return newNdInterpolation(g, false)
+}
+
+/*
+Constructs a tensor-product cubic interpolation of 'g'
+(continuously differentiable, exact for quadratics along
+each axis)
+
+Returns the same errors as NdLinear.
+*/
+func NdCubic(g *NdGrid) (*NdInterpolation, error) {
+	return newNdInterpolation(g, true)
+}
+
+func newNdInterpolation(g *NdGrid, cubic bool) (*NdInterpolation, error) {
+	D := len(g.Axes)
+	if D == 0 {
+		return nil, ErrLength
+	}
+	strides := make([]int, D)
+	size := 1
+	for d := D - 1; d >= 0; d-- {
+		ax := g.Axes[d]
+		if len(ax) < 2 {
+			return nil, ErrLength
+		}
+		if err := checkAxis(ax); err != nil {
+			return nil, err
+		}
+		strides[d] = size
+		size *= len(ax)
+	}
+	if size != len(g.Values) {
+		return nil, ErrLength
+	}
+	return &NdInterpolation{grid: g, strides: strides, cubic: cubic}, nil
+}
+
+//Number of axes
+func (n *NdInterpolation) Dim() int {
+	return len(n.grid.Axes)
+}
+
+//Returns the interpolated value at 'pt'
+func (n *NdInterpolation) F(pt []float64) float64 {
+	out, _ := n.eval(pt, false)
+	return out
+}
+
+/*
+Returns the interpolated value and its gradient at 'pt'
+
+Returns NaN (and a nil gradient) if len(pt) is not Dim()
+or 'pt' is not rational.
+*/
+func (n *NdInterpolation) Eval(pt []float64) (float64, []float64) {
+	return n.eval(pt, true)
+}
+
+/*
+Evaluates the values (and gradients) at each of 'pts'
+
+The points are split into NumCPU() contiguous pieces,
+each sent to a pool of NumCPU() workers; the gradients
+are only computed if 'grads' is true.
+*/
+func (n *NdInterpolation) EvalBatch(pts [][]float64, grads bool) ([]float64, [][]float64) {
+	vals := make([]float64, len(pts))
+	var gs [][]float64
+	if grads {
+		gs = make([][]float64, len(pts))
+	}
+	evalRange := func(s int, e int) {
+		for i := s; i < e; i++ {
+			if grads {
+				vals[i], gs[i] = n.eval(pts[i], true)
+			} else {
+				vals[i], _ = n.eval(pts[i], false)
+			}
+		}
+	}
+
+	NTHREADS := runtime.NumCPU()
+	l := len(pts)
+	batch_size := l / NTHREADS
+	if NTHREADS <= 1 || batch_size == 0 {
+		evalRange(0, l)
+		return vals, gs
+	}
+
+	p := pool.NewPool(NTHREADS, NTHREADS)
+	for i := 0; i < NTHREADS; i++ {
+		start, end := i*batch_size, (i+1)*batch_size
+		//last piece takes the remainder
+		if i == NTHREADS-1 {
+			end = l
+		}
+		p.Send(func() { evalRange(start, end) })
+	}
+	p.WaitAll()
+	return vals, gs
+}
+
+/*
+Per-axis weights: the value along one axis is
+sum(w[k]*y[base+k]) and its derivative sum(dw[k]*y[base+k])
+*/
+type axisWeights struct {
+	base  int
+	w, dw [4]float64
+}
+
+func (n *NdInterpolation) eval(pt []float64, grad bool) (float64, []float64) {
+	D := len(n.grid.Axes)
+	if len(pt) != D {
+		return math.NaN(), nil
+	}
+	aw := make([]axisWeights, D)
+	for d, x := range pt {
+		if notRat(x) {
+			return math.NaN(), nil
+		}
+		if n.cubic {
+			aw[d] = cubicWeights(n.grid.Axes[d], x)
+		} else {
+			aw[d] = linearWeights(n.grid.Axes[d], x)
+		}
+	}
+
+	width := 2
+	if n.cubic {
+		width = 4
+	}
+	var g []float64
+	if grad {
+		g = make([]float64, D)
+	}
+
+	//walk every corner of the stencil (width^D of them)
+	out := 0.0
+	off := make([]int, D)
+	for {
+		flat, in, w := 0, true, 1.0
+		for d, k := range off {
+			idx := aw[d].base + k
+			if idx < 0 || idx >= len(n.grid.Axes[d]) || aw[d].w[k] == 0 && aw[d].dw[k] == 0 {
+				in = false
+				break
+			}
+			flat += idx * n.strides[d]
+			w *= aw[d].w[k]
+		}
+		if in {
+			y := n.grid.Values[flat]
+			out += w * y
+			for j := range g {
+				dw := aw[j].dw[off[j]]
+				for d, k := range off {
+					if d != j {
+						dw *= aw[d].w[k]
+					}
+				}
+				g[j] += dw * y
+			}
+		}
+
+		//next corner
+		d := D - 1
+		for d >= 0 {
+			off[d]++
+			if off[d] < width {
+				break
+			}
+			off[d] = 0
+			d--
+		}
+		if d < 0 {
+			break
+		}
+	}
+	return out, g
+}
+
+func linearWeights(xs []float64, x float64) axisWeights {
+	i := bracket(xs, x)
+	h := xs[i+1] - xs[i]
+	t := (x - xs[i]) / h
+	return axisWeights{base: i, w: [4]float64{1 - t, t}, dw: [4]float64{-1 / h, 1 / h}}
+}
+
+/*
+Cubic Hermite weights on the stencil xs[i-1] ... xs[i+2],
+with the knot slopes written as combinations of the
+neighbouring values (see fdWeights)
+*/
+func cubicWeights(xs []float64, x float64) axisWeights {
+	i := bracket(xs, x)
+	h := xs[i+1] - xs[i]
+	t := (x - xs[i]) / h
+	aw := axisWeights{base: i - 1}
+
+	//Hermite basis and its t-derivative
+	h00, h01 := 1-3*t*t+2*t*t*t, 3*t*t-2*t*t*t
+	h10, h11 := t-2*t*t+t*t*t, t*t*t-t*t
+	d00, d01 := -6*t+6*t*t, 6*t-6*t*t
+	d10, d11 := 1-4*t+3*t*t, 3*t*t-2*t
+
+	aw.w[1] += h00
+	aw.w[2] += h01
+	aw.dw[1] += d00 / h
+	aw.dw[2] += d01 / h
+	for k, b := range [2][2]float64{{h10, d10}, {h11, d11}} {
+		lo, c := fdWeights(xs, i+k)
+		for j := range c {
+			aw.w[lo+j-aw.base] += h * b[0] * c[j]
+			aw.dw[lo+j-aw.base] += b[1] * c[j]
+		}
+	}
+	return aw
+}
+
+/*
+Coefficients of y[lo], y[lo+1], y[lo+2] in the
+finite-difference slope at knot 'k' computed by fdSlopes()
+*/
+func fdWeights(xs []float64, k int) (lo int, c [3]float64) {
+	n := len(xs)
+	if n == 2 {
+		h := xs[1] - xs[0]
+		return 0, [3]float64{-1 / h, 1 / h, 0}
+	}
+	switch k {
+	case 0:
+		h0, h1 := xs[1]-xs[0], xs[2]-xs[1]
+		s := h0 + h1
+		a := (2*h0 + h1) / (h0 * s)
+		b := h0 / (h1 * s)
+		return 0, [3]float64{-a, a + b, -b}
+	case n - 1:
+		h0, h1 := xs[n-1]-xs[n-2], xs[n-2]-xs[n-3]
+		s := h0 + h1
+		a := (2*h0 + h1) / (h0 * s)
+		b := h0 / (h1 * s)
+		return n - 3, [3]float64{b, -a - b, a}
+	}
+	h0, h1 := xs[k]-xs[k-1], xs[k+1]-xs[k]
+	s := h0 + h1
+	return k - 1, [3]float64{-h1 / (h0 * s), (h1/h0 - h0/h1) / s, h0 / (h1 * s)}
+}
@@ -0,0 +1,82 @@
+package vec
+
+import "testing"
+import "math"
+
+//tabulates 'f' on a small uneven 3-D grid
+func makeNdGrid(f func([]float64) float64) *NdGrid {
+	g := &NdGrid{Axes: [][]float64{{0, 0.5, 1.5, 2}, {-1, 0, 1}, {0, 0.1, 0.3, 0.7, 1}}}
+	for _, x := range g.Axes[0] {
+		for _, y := range g.Axes[1] {
+			for _, z := range g.Axes[2] {
+				g.Values = append(g.Values, f([]float64{x, y, z}))
+			}
+		}
+	}
+	return g
+}
+
+var ndPts = [][]float64{{0.2, -0.7, 0.05}, {1.9, 0.5, 0.5}, {1.0, 0.0, 0.99}, {2.5, 1.2, -0.1}}
+
+//Multilinear interpolation reproduces multilinear functions
+func TestNdLinear(t *testing.T) {
+	f := func(p []float64) float64 {
+		return 1 + p[0] + 2*p[1] - p[2] + p[0]*p[1]*p[2]
+	}
+	n, _ := NdLinear(makeNdGrid(f))
+	for _, p := range ndPts {
+		v, g := n.Eval(p)
+		if math.Abs(v-f(p)) > 1E-13 {
+			t.Error("NdLinear inaccurate at", p, "Got:", v, "Expected:", f(p))
+		}
+		if math.Abs(g[0]-(1+p[1]*p[2])) > 1E-13 || math.Abs(g[2]-(-1+p[0]*p[1])) > 1E-13 {
+			t.Error("NdLinear gradient inaccurate at", p, "Got:", g)
+		}
+	}
+	if _, err := NdLinear(&NdGrid{Axes: [][]float64{{0, 1}}, Values: []float64{1}}); err != ErrLength {
+		t.Error("NdLinear accepted a malformed grid. Got:", err)
+	}
+	if _, err := NdCubic(&NdGrid{Axes: [][]float64{{1, 0}}, Values: []float64{1, 2}}); err != ErrUnordered {
+		t.Error("NdCubic accepted an unsorted axis. Got:", err)
+	}
+	if _, err := NdLinear(&NdGrid{Axes: [][]float64{{0, 1, 1}}, Values: []float64{1, 2, 3}}); err != ErrUnordered {
+		t.Error("NdLinear accepted repeated knots. Got:", err)
+	}
+	if _, err := NdLinear(&NdGrid{Axes: [][]float64{{0, math.Inf(1)}}, Values: []float64{1, 2}}); err != ErrNotFinite {
+		t.Error("NdLinear accepted an infinite knot. Got:", err)
+	}
+}
+
+/*
+Tensor-product cubic interpolation reproduces functions
+that are quadratic along every axis
+
+- batch evaluation matches point-by-point evaluation
+*/
+func TestNdCubic(t *testing.T) {
+	f := func(p []float64) float64 {
+		return p[0]*p[0] + p[1]*p[2] - p[2]*p[2] + 2*p[0]*p[0]*p[1]
+	}
+	n, _ := NdCubic(makeNdGrid(f))
+	for _, p := range ndPts {
+		v, g := n.Eval(p)
+		if math.Abs(v-f(p)) > 1E-12 {
+			t.Error("NdCubic inaccurate at", p, "Got:", v, "Expected:", f(p))
+		}
+		grad := []float64{2*p[0] + 4*p[0]*p[1], p[2] + 2*p[0]*p[0], p[1] - 2*p[2]}
+		for d := range grad {
+			if math.Abs(g[d]-grad[d]) > 1E-12 {
+				t.Error("NdCubic gradient inaccurate at", p, "Got:", g, "Expected:", grad)
+				break
+			}
+		}
+	}
+
+	vals, grads := n.EvalBatch(ndPts, true)
+	for i, p := range ndPts {
+		v, g := n.Eval(p)
+		if vals[i] != v || grads[i][1] != g[1] {
+			t.Error("NdCubic.EvalBatch disagrees with Eval at", p)
+		}
+	}
+}
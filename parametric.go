@@ -0,0 +1,264 @@
+package vec
+
+import (
+	"errors"
+	"math"
+)
+
+/*
+Parametric splines through ordered points
+
+Each coordinate is a cubic spline in a common parameter t,
+so the curve may double back on itself (contours,
+trajectories) where y is not a function of x. Closed curves
+use Periodic end conditions, which keeps the tangent and
+curvature continuous across the seam.
+*/
+
+//Parameterization - how the knot parameters t[i] are spaced
+type Parameterization int
+
+const (
+	//ChordLength - t[i+1]-t[i] = |p[i+1]-p[i]|
+	ChordLength Parameterization = iota
+	//Centripetal - t[i+1]-t[i] = sqrt(|p[i+1]-p[i]|) (avoids cusps and self-intersections)
+	Centripetal
+	//UniformParam - t[i+1]-t[i] = 1
+	UniformParam
+)
+
+//ErrCoincident - consecutive points at the same position
+var ErrCoincident = errors.New("vec: coincident consecutive points")
+
+//ParametricSpline type def
+type ParametricSpline struct {
+	ts     []float64
+	coords []*CubicSplineInterpolation
+	closed bool
+	toT    *CubicSplineInterpolation //arc length -> t (nil unless reparameterised)
+}
+
+/*
+Constructs a parametric spline through the points 'pts'
+(in order, all of the same dimension). If 'closed', the
+curve returns to the first point; the first point need not
+be repeated at the end.
+
+Returns ErrLength if there are too few points or they are
+ragged, and ErrCoincident if two consecutive points coincide.
+*/
+func ParametricCurve(pts [][]float64, param Parameterization, closed bool) (*ParametricSpline, error) {
+	N := len(pts)
+	if N < 2 || len(pts[0]) == 0 {
+		return nil, ErrLength
+	}
+	D := len(pts[0])
+	for _, p := range pts {
+		if len(p) != D {
+			return nil, ErrLength
+		}
+	}
+	if closed && dist(pts[0], pts[N-1]) > 0 {
+		pts = append(pts[:N:N], pts[0])
+		N++
+	}
+	if closed && N < 3 {
+		return nil, ErrLength
+	}
+
+	ts := make([]float64, N)
+	for i := 1; i < N; i++ {
+		c := dist(pts[i], pts[i-1])
+		if c == 0 {
+			return nil, ErrCoincident
+		}
+		switch param {
+		case Centripetal:
+			c = math.Sqrt(c)
+		case UniformParam:
+			c = 1
+		}
+		ts[i] = ts[i-1] + c
+	}
+
+	ends := SplineEnds{Cond: Natural}
+	if closed {
+		ends.Cond = Periodic
+	}
+	p := &ParametricSpline{ts: ts, coords: make([]*CubicSplineInterpolation, D), closed: closed}
+	for k := range p.coords {
+		ys := make([]float64, N)
+		for i := range pts {
+			ys[i] = pts[i][k]
+		}
+		if closed {
+			ys[N-1] = ys[0]
+		}
+		//closed curves are periodic by construction
+		p.coords[k], _ = CubicSplineEnds(&BiVariateData{Xs: ts, Ys: ys, isSorted: true}, ends)
+	}
+	return p, nil
+}
+
+//Returns the dimension of the curve
+func (p *ParametricSpline) Dim() int {
+	return len(p.coords)
+}
+
+//Returns the parameter range: [0, T], or [0, Length()] after ByArcLength()
+func (p *ParametricSpline) Domain() (float64, float64) {
+	if p.toT != nil {
+		_, L := p.toT.Domain()
+		return 0, L
+	}
+	return 0, p.ts[len(p.ts)-1]
+}
+
+//maps the parameter 'u' to the knot parameter t and dt/du (closed curves wrap around)
+func (p *ParametricSpline) param(u float64) (float64, float64) {
+	if p.closed {
+		_, P := p.Domain()
+		u -= P * math.Floor(u/P)
+	}
+	if p.toT == nil {
+		return u, 1
+	}
+	return p.toT.F(u), p.toT.DF(u)
+}
+
+//Evaluates the point on the curve at 'u'
+func (p *ParametricSpline) At(u float64) []float64 {
+	t, _ := p.param(u)
+	out := make([]float64, len(p.coords))
+	for k, c := range p.coords {
+		out[k] = c.F(t)
+	}
+	return out
+}
+
+//Evaluates the derivative of the curve with respect to 'u'
+func (p *ParametricSpline) Deriv(u float64) []float64 {
+	t, dt := p.param(u)
+	out := make([]float64, len(p.coords))
+	for k, c := range p.coords {
+		out[k] = c.DF(t) * dt
+	}
+	return out
+}
+
+//Evaluates the unit tangent at 'u'
+func (p *ParametricSpline) Tangent(u float64) []float64 {
+	t, _ := p.param(u)
+	out := make([]float64, len(p.coords))
+	norm := p.speed(t)
+	for k, c := range p.coords {
+		out[k] = c.DF(t) / norm
+	}
+	return out
+}
+
+/*
+Evaluates the curvature at 'u'
+
+In two dimensions the curvature is signed (positive when
+the curve turns counter-clockwise); otherwise it is
+|dr x d2r| / |dr|^3 (dr, d2r the first and second derivatives).
+*/
+func (p *ParametricSpline) Curvature(u float64) float64 {
+	t, _ := p.param(u)
+	if len(p.coords) == 2 {
+		x, y := p.coords[0], p.coords[1]
+		return (x.DF(t)*y.DDF(t) - y.DF(t)*x.DDF(t)) / math.Pow(p.speed(t), 3)
+	}
+	var d1, d2, d12 float64
+	for _, c := range p.coords {
+		a, b := c.DF(t), c.DDF(t)
+		d1 += a * a
+		d2 += b * b
+		d12 += a * b
+	}
+	return math.Sqrt(math.Max(d1*d2-d12*d12, 0)) / math.Pow(d1, 1.5)
+}
+
+//|dr/dt| at knot parameter 't'
+func (p *ParametricSpline) speed(t float64) float64 {
+	s := 0.0
+	for _, c := range p.coords {
+		d := c.DF(t)
+		s += d * d
+	}
+	return math.Sqrt(s)
+}
+
+//Returns the total arc length of the curve
+func (p *ParametricSpline) Length() float64 {
+	return p.tLength(0, p.ts[len(p.ts)-1])
+}
+
+/*
+Returns the arc length between parameters 'u1' and 'u2'
+(negative if u2 < u1). After ByArcLength() this is simply
+u2 - u1.
+*/
+func (p *ParametricSpline) ArcLength(u1 float64, u2 float64) float64 {
+	if p.toT != nil {
+		return u2 - u1
+	}
+	return p.tLength(u1, u2)
+}
+
+//arc length between knot parameters, integrated per knot interval
+func (p *ParametricSpline) tLength(a float64, b float64) float64 {
+	if a > b {
+		return -p.tLength(b, a)
+	}
+	d := p.coords[0].data
+	seg := func(i int, t1 float64, t2 float64) float64 {
+		return gaussLegendre(p.speed, t1, t2)
+	}
+	if !p.closed {
+		return segSum(d, a, b, seg)
+	}
+
+	//closed curves: shift into the first period and add whole turns
+	T := p.ts[len(p.ts)-1]
+	shift := T * math.Floor(a/T)
+	a, b = a-shift, b-shift
+	out := 0.0
+	for b > T {
+		out += segSum(d, a, T, seg)
+		a, b = 0, b-T
+	}
+	return out + segSum(d, a, b, seg)
+}
+
+/*
+Returns the same curve parameterised by arc length, so that
+At(s) is the point a distance 's' along the curve from the
+first point and |Deriv(s)| = 1.
+
+The map from arc length to the knot parameter is a monotone
+spline through the arc length sampled 16 times per knot
+interval.
+*/
+func (p *ParametricSpline) ByArcLength() *ParametricSpline {
+	const sub = 16
+	n := len(p.ts) - 1
+	ss := make([]float64, n*sub+1)
+	ts := make([]float64, n*sub+1)
+	for i := 0; i < n; i++ {
+		h := (p.ts[i+1] - p.ts[i]) / sub
+		for j := 1; j <= sub; j++ {
+			k := i*sub + j
+			ts[k] = p.ts[i] + float64(j)*h
+			ss[k] = ss[k-1] + gaussLegendre(p.speed, ts[k-1], ts[k])
+		}
+		ts[(i+1)*sub] = p.ts[i+1]
+	}
+	return &ParametricSpline{
+		ts:     p.ts,
+		coords: p.coords,
+		closed: p.closed,
+		toT:    MonotoneSpline(&BiVariateData{Xs: ss, Ys: ts, isSorted: true}),
+	}
+}
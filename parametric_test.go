@@ -0,0 +1,112 @@
+package vec
+
+import "testing"
+import "math"
+
+//'N' evenly spaced points on the unit circle
+func circlePts(N int) [][]float64 {
+	pts := make([][]float64, N)
+	for i := range pts {
+		th := 2 * math.Pi * float64(i) / float64(N)
+		pts[i] = []float64{math.Cos(th), math.Sin(th)}
+	}
+	return pts
+}
+
+/*
+Test closed parametric curves on a circle
+
+- length, curvature, and tangents perpendicular to the radius
+- the curve wraps around its period
+- reparameterising by arc length gives unit speed
+*/
+func TestParametricCircle(t *testing.T) {
+	for _, param := range []Parameterization{ChordLength, Centripetal, UniformParam} {
+		p, err := ParametricCurve(circlePts(32), param, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if relativeError(p.Length(), 2*math.Pi) > 1E-4 {
+			t.Error("Circle length is wrong. Got:", p.Length(), "Expected:", 2*math.Pi)
+		}
+		_, T := p.Domain()
+		for _, u := range Arange(0, T, 9) {
+			if k := p.Curvature(u); math.Abs(k-1) > 1E-2 {
+				t.Error("Circle curvature is wrong at", u, "Got:", k, "Expected:", 1)
+			}
+			pt := p.At(u)
+			if math.Abs(math.Hypot(pt[0], pt[1])-1) > 1E-4 {
+				t.Error("Point is not on the unit circle. Got:", pt)
+			}
+			tan := p.Tangent(u)
+			if math.Abs(tan[0]*pt[0]+tan[1]*pt[1]) > 1E-3 {
+				t.Error("Tangent is not perpendicular to the radius. Got:", tan, pt)
+			}
+		}
+
+		//closed curves wrap around
+		a, b := p.At(0.3), p.At(0.3+T)
+		if math.Abs(a[0]-b[0]) > 1E-12 || math.Abs(a[1]-b[1]) > 1E-12 {
+			t.Error("Closed curve did not wrap around.")
+		}
+
+		s := p.ByArcLength()
+		_, L := s.Domain()
+		if relativeError(L, p.Length()) > 1E-9 {
+			t.Error("Arc length domain is wrong. Got:", L, "Expected:", p.Length())
+		}
+		for _, u := range Arange(0, L, 13) {
+			d := s.Deriv(u)
+			if math.Abs(math.Hypot(d[0], d[1])-1) > 1E-3 {
+				t.Error("Speed is wrong at arc length", u, "Got:", math.Hypot(d[0], d[1]), "Expected:", 1)
+			}
+			pt := s.At(u)
+			if math.Abs(math.Atan2(pt[1], pt[0])-math.Remainder(u, 2*math.Pi)) > 1E-3 {
+				t.Error("Point is wrong at arc length", u, "Got:", pt)
+			}
+		}
+	}
+}
+
+/*
+Test an open parametric curve on a helix, which doubles
+back in every coordinate
+
+- length and curvature
+- reversed arc length is negative
+
+EDGE CASES:
+- ragged points return ErrLength
+- repeated points return ErrCoincident
+*/
+func TestParametricOpen(t *testing.T) {
+	pts := make([][]float64, 41)
+	for i := range pts {
+		th := float64(i) / 4
+		pts[i] = []float64{math.Cos(th), math.Sin(th), th}
+	}
+	p, err := ParametricCurve(pts, ChordLength, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Dim() != 3 {
+		t.Error("Expected a 3-dimensional curve. Got:", p.Dim())
+	}
+	if relativeError(p.Length(), 10*math.Sqrt2) > 1E-3 {
+		t.Error("Helix length is wrong. Got:", p.Length(), "Expected:", 10*math.Sqrt2)
+	}
+	_, T := p.Domain()
+	if k := p.Curvature(T / 2); math.Abs(k-0.5) > 1E-2 {
+		t.Error("Helix curvature is wrong. Got:", k, "Expected:", 0.5)
+	}
+	if relativeError(p.ArcLength(T, 0), -p.Length()) > 1E-12 {
+		t.Error("Reversed arc length should be negative.")
+	}
+
+	if _, err := ParametricCurve([][]float64{{0, 0}, {1}}, ChordLength, false); err != ErrLength {
+		t.Error("Expected ErrLength for ragged points. Got:", err)
+	}
+	if _, err := ParametricCurve([][]float64{{0, 0}, {0, 0}, {1, 1}}, ChordLength, false); err != ErrCoincident {
+		t.Error("Expected ErrCoincident for repeated points. Got:", err)
+	}
+}
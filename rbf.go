@@ -0,0 +1,237 @@
+package vec
+
+import "math"
+
+/*
+Radial basis function interpolation of scattered data
+
+s(x) = sum(c[j]*phi(|x - x[j]|)) + p(x)
+
+where p is an optional polynomial tail (constant or linear)
+whose coefficients are constrained by sum(c[j]*q(x[j])) = 0
+for every tail monomial q. The (N + tail) dense system is
+solved once at construction.
+*/
+
+//RBFKernel - radial function phi(r)
+type RBFKernel int
+
+const (
+	//RBFGaussian - exp(-(eps*r)^2)
+	RBFGaussian RBFKernel = iota
+	//RBFMultiquadric - sqrt(1 + (eps*r)^2)
+	RBFMultiquadric
+	//RBFInverseMultiquadric - 1/sqrt(1 + (eps*r)^2)
+	RBFInverseMultiquadric
+	//RBFThinPlate - r^2 * log(r)
+	RBFThinPlate
+	//RBFCubic - r^3
+	RBFCubic
+)
+
+//RBFTail - polynomial added to the radial functions
+type RBFTail int
+
+const (
+	//TailNone - no polynomial
+	TailNone RBFTail = iota
+	//TailConstant - constant term
+	TailConstant
+	//TailLinear - constant and linear terms (recommended for RBFThinPlate)
+	TailLinear
+)
+
+/*
+RBFOptions - parameters for RBF()
+
+'Epsilon' is the shape parameter of the Gaussian and
+(inverse) multiquadric kernels; zero selects the typical
+spacing of the points. 'Smoothing' is added to the diagonal
+of the system: zero interpolates exactly, larger values
+fit noisy data more loosely.
+*/
+type RBFOptions struct {
+	Kernel    RBFKernel
+	Epsilon   float64
+	Smoothing float64
+	Tail      RBFTail
+}
+
+//RBFInterpolation type def
+type RBFInterpolation struct {
+	centers [][]float64
+	coeffs  []float64
+	tail    []float64
+	opts    RBFOptions
+}
+
+/*
+Constructs an RBF interpolation through the values 'vals'
+at the points 'pts' (all of the same dimension)
+
+Returns ErrLength if the points are ragged or don't match
+'vals', and ErrSingular if the system cannot be solved
+(e.g. repeated points without smoothing, or a linear
+tail on collinear points).
+*/
+func RBF(pts [][]float64, vals []float64, opts RBFOptions) (*RBFInterpolation, error) {
+	N := len(pts)
+	if N == 0 || N != len(vals) {
+		return nil, ErrLength
+	}
+	D := len(pts[0])
+	for _, p := range pts {
+		if len(p) != D {
+			return nil, ErrLength
+		}
+	}
+	if opts.Epsilon == 0 {
+		opts.Epsilon = 1.0 / typicalSpacing(pts)
+	}
+	r := &RBFInterpolation{centers: pts, opts: opts}
+
+	M := 0
+	switch opts.Tail {
+	case TailConstant:
+		M = 1
+	case TailLinear:
+		M = 1 + D
+	}
+
+	A := zeros(N+M, N+M)
+	b := make([]float64, N+M)
+	copy(b, vals)
+	for i := 0; i < N; i++ {
+		for j := 0; j < i; j++ {
+			A[i][j] = r.phi(dist(pts[i], pts[j]))
+			A[j][i] = A[i][j]
+		}
+		A[i][i] = r.phi(0) + opts.Smoothing
+		for k := 0; k < M; k++ {
+			A[i][N+k] = monomial(pts[i], k)
+			A[N+k][i] = A[i][N+k]
+		}
+	}
+
+	sol, err := solveDense(A, b)
+	if err != nil {
+		return nil, err
+	}
+	r.coeffs, r.tail = sol[:N], sol[N:]
+	return r, nil
+}
+
+//k-th tail monomial: 1, x[0], x[1], ...
+func monomial(x []float64, k int) float64 {
+	if k == 0 {
+		return 1.0
+	}
+	return x[k-1]
+}
+
+//Euclidean distance
+func dist(a []float64, b []float64) float64 {
+	s := 0.0
+	for i := range a {
+		s += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return math.Sqrt(s)
+}
+
+//edge of the bounding box divided evenly among the points
+func typicalSpacing(pts [][]float64) float64 {
+	D := len(pts[0])
+	vol, dims := 1.0, 0
+	for d := 0; d < D; d++ {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, p := range pts {
+			lo, hi = math.Min(lo, p[d]), math.Max(hi, p[d])
+		}
+		if hi > lo {
+			vol *= hi - lo
+			dims++
+		}
+	}
+	if dims == 0 {
+		return 1.0
+	}
+	return math.Pow(vol/float64(len(pts)), 1.0/float64(dims))
+}
+
+//radial function
+func (r *RBFInterpolation) phi(x float64) float64 {
+	e := r.opts.Epsilon
+	switch r.opts.Kernel {
+	case RBFMultiquadric:
+		return math.Sqrt(1 + e*e*x*x)
+	case RBFInverseMultiquadric:
+		return 1.0 / math.Sqrt(1+e*e*x*x)
+	case RBFThinPlate:
+		if x == 0 {
+			return 0.0
+		}
+		return x * x * math.Log(x)
+	case RBFCubic:
+		return x * x * x
+	}
+	return math.Exp(-e * e * x * x)
+}
+
+//phi'(r)/r (zero at r = 0, where the gradient of every kernel vanishes)
+func (r *RBFInterpolation) dphiOverR(x float64) float64 {
+	if x == 0 {
+		return 0.0
+	}
+	e := r.opts.Epsilon
+	switch r.opts.Kernel {
+	case RBFMultiquadric:
+		return e * e / math.Sqrt(1+e*e*x*x)
+	case RBFInverseMultiquadric:
+		return -e * e / math.Pow(1+e*e*x*x, 1.5)
+	case RBFThinPlate:
+		return 2*math.Log(x) + 1
+	case RBFCubic:
+		return 3 * x
+	}
+	return -2 * e * e * math.Exp(-e*e*x*x)
+}
+
+//Dimension of the points
+func (r *RBFInterpolation) Dim() int {
+	return len(r.centers[0])
+}
+
+//Returns the interpolated value at 'pt'
+func (r *RBFInterpolation) F(pt []float64) float64 {
+	out, _ := r.Eval(pt)
+	return out
+}
+
+/*
+Returns the interpolated value and its gradient at 'pt'
+
+Returns NaN (and a nil gradient) if len(pt) is not Dim().
+*/
+func (r *RBFInterpolation) Eval(pt []float64) (float64, []float64) {
+	D := r.Dim()
+	if len(pt) != D {
+		return math.NaN(), nil
+	}
+	out := 0.0
+	grad := make([]float64, D)
+	for j, c := range r.centers {
+		x := dist(pt, c)
+		out += r.coeffs[j] * r.phi(x)
+		g := r.coeffs[j] * r.dphiOverR(x)
+		for d := range grad {
+			grad[d] += g * (pt[d] - c[d])
+		}
+	}
+	for k, t := range r.tail {
+		out += t * monomial(pt, k)
+		if k > 0 {
+			grad[k-1] += t
+		}
+	}
+	return out, grad
+}
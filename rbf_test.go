@@ -0,0 +1,65 @@
+package vec
+
+import "testing"
+import "math"
+import "math/rand"
+
+func scattered(N int, f func([]float64) float64) ([][]float64, []float64) {
+	rnd := rand.New(rand.NewSource(1))
+	pts := make([][]float64, N)
+	vals := make([]float64, N)
+	for i := range pts {
+		pts[i] = []float64{rnd.Float64() * 2, rnd.Float64()*3 - 1}
+		vals[i] = f(pts[i])
+	}
+	return pts, vals
+}
+
+/*
+Test RBF interpolation
+
+- every kernel passes through the data
+- gradients agree with finite differences
+- thin-plate splines with a linear tail reproduce planes
+*/
+func TestRBF(t *testing.T) {
+	f := func(p []float64) float64 {
+		return math.Sin(p[0]) * math.Cos(p[1])
+	}
+	pts, vals := scattered(60, f)
+
+	for _, k := range []RBFKernel{RBFGaussian, RBFMultiquadric, RBFInverseMultiquadric, RBFThinPlate, RBFCubic} {
+		r, err := RBF(pts, vals, RBFOptions{Kernel: k, Tail: TailLinear})
+		if err != nil {
+			t.Error("RBF failed for kernel", k, err)
+			continue
+		}
+		for i, p := range pts {
+			if math.Abs(r.F(p)-vals[i]) > 1E-6 {
+				t.Error("RBF kernel", k, "does not pass through the data. Got:", r.F(p), "Expected:", vals[i])
+				break
+			}
+		}
+		x := []float64{1.1, 0.4}
+		_, g := r.Eval(x)
+		const h = 1E-6
+		dx := (r.F([]float64{x[0] + h, x[1]}) - r.F([]float64{x[0] - h, x[1]})) / (2 * h)
+		dy := (r.F([]float64{x[0], x[1] + h}) - r.F([]float64{x[0], x[1] - h})) / (2 * h)
+		if math.Abs(g[0]-dx) > 1E-5 || math.Abs(g[1]-dy) > 1E-5 {
+			t.Error("RBF kernel", k, "gradient is wrong. Got:", g, "Expected:", dx, dy)
+		}
+	}
+
+	plane := func(p []float64) float64 {
+		return 3 - p[0] + 2*p[1]
+	}
+	pts, vals = scattered(20, plane)
+	r, _ := RBF(pts, vals, RBFOptions{Kernel: RBFThinPlate, Tail: TailLinear, Smoothing: 0.1})
+	if math.Abs(r.F([]float64{0.5, 0.5})-plane([]float64{0.5, 0.5})) > 1E-10 {
+		t.Error("Thin-plate RBF does not reproduce a plane. Got:", r.F([]float64{0.5, 0.5}))
+	}
+
+	if _, err := RBF(append(pts, pts[0]), append(vals, vals[0]), RBFOptions{Kernel: RBFCubic}); err != ErrSingular {
+		t.Error("RBF accepted a repeated point without smoothing")
+	}
+}
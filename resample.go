@@ -0,0 +1,146 @@
+package vec
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+Resampling, decimation and binning of BiVariateData
+*/
+
+/*
+Samples the interpolation 'it' at 'xs' and returns the
+samples as a new BiVariateData ('xs' is copied and sorted)
+
+e.g. uniform := Resample(CubicSpline(bvd), Arange(0, 10, 1024))
+*/
+func Resample(it Interpolator, xs []float64) *BiVariateData {
+	out := &BiVariateData{Xs: append([]float64{}, xs...), Ys: make([]float64, len(xs))}
+	out.Sort()
+	for i, x := range out.Xs {
+		out.Ys[i] = it.F(x)
+	}
+	return out
+}
+
+/*
+Samples 'it' at 'N' equally spaced points spanning its
+domain, both endpoints included (N >= 2)
+*/
+func ResampleUniform(it Interpolator, N int) *BiVariateData {
+	if N < 2 {
+		return &BiVariateData{Xs: []float64{}, Ys: []float64{}, isSorted: true}
+	}
+	lo, hi := it.Domain()
+	xs := Arange(lo, hi+(hi-lo)/float64(N-1), N)
+	xs[N-1] = hi
+	return Resample(it, xs)
+}
+
+/*
+Reduces 'b' by 'factor', replacing each block of 'factor'
+consecutive points by their mean x and mean y
+
+The block average is a boxcar anti-alias filter: on evenly
+spaced data it suppresses content above the new Nyquist
+frequency before the sample rate drops. A final partial
+block is dropped so that the spacing stays even. Returns
+ErrParam if 'factor' is less than one.
+*/
+func Decimate(b *BiVariateData, factor int) (*BiVariateData, error) {
+	if factor < 1 {
+		return nil, ErrParam
+	}
+	b.Sort()
+	n := len(b.Xs) / factor
+	out := &BiVariateData{Xs: make([]float64, n), Ys: make([]float64, n), isSorted: true}
+	for i := 0; i < n; i++ {
+		var sx, sy float64
+		for k := i * factor; k < (i+1)*factor; k++ {
+			sx += b.Xs[k]
+			sy += b.Ys[k]
+		}
+		out.Xs[i] = sx / float64(factor)
+		out.Ys[i] = sy / float64(factor)
+	}
+	return out, nil
+}
+
+//BinStat - statistic of the y-values in each bin
+type BinStat int
+
+const (
+	//BinMean - mean y-value (NaN for an empty bin)
+	BinMean BinStat = iota
+	//BinMedian - median y-value (NaN for an empty bin)
+	BinMedian
+	//BinCount - number of points
+	BinCount
+)
+
+/*
+Groups the points of 'b' into bins by x-value and returns
+'stat' of each bin against the bin centre
+
+Bin i covers [edges[i], edges[i+1]); the last bin also
+includes its right edge, and points outside the edges are
+ignored. Returns ErrLength if there are fewer than two edges,
+and ErrUnordered if they are not strictly increasing.
+*/
+func BinByX(b *BiVariateData, edges []float64, stat BinStat) (*BiVariateData, error) {
+	nb := len(edges) - 1
+	if nb < 1 {
+		return nil, ErrLength
+	}
+	for i := 1; i < len(edges); i++ {
+		if !(edges[i] > edges[i-1]) {
+			return nil, ErrUnordered
+		}
+	}
+	b.Sort()
+
+	out := &BiVariateData{Xs: make([]float64, nb), Ys: make([]float64, nb), isSorted: true}
+	j := sort.SearchFloat64s(b.Xs, edges[0])
+	for i := 0; i < nb; i++ {
+		out.Xs[i] = (edges[i] + edges[i+1]) / 2.0
+
+		//points j to k lie in bin i
+		k := j
+		for k < len(b.Xs) && (b.Xs[k] < edges[i+1] || (i == nb-1 && b.Xs[k] == edges[nb])) {
+			k++
+		}
+		ys := b.Ys[j:k]
+		switch stat {
+		case BinCount:
+			out.Ys[i] = float64(len(ys))
+		case BinMedian:
+			out.Ys[i] = median(ys)
+		default:
+			out.Ys[i] = math.NaN()
+			if len(ys) > 0 {
+				s := 0.0
+				for _, y := range ys {
+					s += y
+				}
+				out.Ys[i] = s / float64(len(ys))
+			}
+		}
+		j = k
+	}
+	return out, nil
+}
+
+//median of 'ys' (NaN if empty); 'ys' is not modified
+func median(ys []float64) float64 {
+	n := len(ys)
+	if n == 0 {
+		return math.NaN()
+	}
+	s := append([]float64{}, ys...)
+	sort.Float64s(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2.0
+}
@@ -0,0 +1,94 @@
+package vec
+
+import "testing"
+import "math"
+
+/*
+Test Resample and ResampleUniform
+
+- a linear interpolation of a line is resampled exactly
+- query points are sorted
+*/
+func TestResample(t *testing.T) {
+	xs := []float64{0, 0.7, 1.1, 2.5, 3.2, 4}
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = 2*x - 1
+	}
+	l := LinearInterp(MakeBiVariateData(xs, ys))
+
+	u := ResampleUniform(l, 9)
+	if len(u.Xs) != 9 || u.Xs[0] != 0 || u.Xs[8] != 4 {
+		t.Error("ResampleUniform grid is wrong. Got:", u.Xs)
+	}
+	for i, x := range u.Xs {
+		if math.Abs(x-0.5*float64(i)) > 1E-14 || math.Abs(u.Ys[i]-(2*x-1)) > 1E-14 {
+			t.Error("ResampleUniform is wrong at", i, "Got:", x, u.Ys[i], "Expected:", 0.5*float64(i), float64(i)-1)
+		}
+	}
+
+	r := Resample(l, []float64{3, 1})
+	if r.Xs[0] != 1 || r.Ys[0] != 1 || r.Ys[1] != 5 {
+		t.Error("Resample is wrong. Got:", r.Xs, r.Ys, "Expected:", []float64{1, 3}, []float64{1, 5})
+	}
+}
+
+/*
+Test Decimate
+
+- the block average removes an alternating component
+- a final partial block is dropped
+
+EDGE CASES:
+- a zero factor returns ErrParam
+*/
+func TestDecimate(t *testing.T) {
+	xs := Arange(0, 10, 10)
+	ys := make([]float64, 10)
+	for i := range ys {
+		ys[i] = 3 + math.Pow(-1, float64(i))
+	}
+	d, _ := Decimate(MakeBiVariateData(xs, ys), 4)
+	if len(d.Xs) != 2 || d.Xs[0] != 1.5 || d.Xs[1] != 5.5 || d.Ys[0] != 3 || d.Ys[1] != 3 {
+		t.Error("Decimate is wrong. Got:", d.Xs, d.Ys, "Expected:", []float64{1.5, 5.5}, []float64{3, 3})
+	}
+	if _, err := Decimate(MakeBiVariateData(xs, ys), 0); err != ErrParam {
+		t.Error("Expected ErrParam for a zero factor. Got:", err)
+	}
+}
+
+/*
+Test BinByX with each statistic
+
+- points outside the edges are ignored
+- the last bin includes its right edge
+
+EDGE CASES:
+- an empty bin has a NaN mean
+- repeated edges return ErrUnordered
+*/
+func TestBinByX(t *testing.T) {
+	xs := []float64{0.5, 0.2, 1.5, 1.7, 1.9, 3, -1, 5}
+	ys := []float64{1, 3, 10, 2, 4, 7, 100, 100}
+	edges := []float64{0, 1, 2, 3}
+	d := MakeBiVariateData(xs, ys)
+
+	mean, _ := BinByX(d, edges, BinMean)
+	median, _ := BinByX(d, edges, BinMedian)
+	count, _ := BinByX(d, edges, BinCount)
+	if mean.Xs[0] != 0.5 || mean.Ys[0] != 2 || mean.Ys[1] != 16.0/3 || mean.Ys[2] != 7 {
+		t.Error("BinByX mean is wrong. Got:", mean.Xs, mean.Ys)
+	}
+	if median.Ys[0] != 2 || median.Ys[1] != 4 || median.Ys[2] != 7 {
+		t.Error("BinByX median is wrong. Got:", median.Ys, "Expected:", []float64{2, 4, 7})
+	}
+	if count.Ys[0] != 2 || count.Ys[1] != 3 || count.Ys[2] != 1 {
+		t.Error("BinByX count is wrong. Got:", count.Ys, "Expected:", []float64{2, 3, 1})
+	}
+	if e, _ := BinByX(d, []float64{10, 11}, BinMean); !math.IsNaN(e.Ys[0]) {
+		t.Error("Expected NaN for the mean of an empty bin. Got:", e.Ys[0])
+	}
+	if _, err := BinByX(d, []float64{1, 1}, BinMean); err != ErrUnordered {
+		t.Error("Expected ErrUnordered for repeated edges. Got:", err)
+	}
+}
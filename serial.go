@@ -0,0 +1,856 @@
+package vec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+)
+
+/*
+Serialization of data and interpolations
+
+Every type is written as a versioned record, either as
+JSON or in a compact little-endian binary format:
+
+	"VEC" | version (1 byte) | type (1 byte) |
+	extrapolation (int32) | degree (int32) |
+	xs, ys, coeffs, knots, errs (each a uint32 count followed by float64s) |
+	axes (a uint32 count of float64 lists) | values |
+	points (a uint32 count of float64 lists) | params
+
+Unknown standard errors (NaN, e.g. from Append) are
+written as null in JSON. Grids store their axes and row-major values and rebuild
+their cells on decoding; RBF interpolations store their
+centers as points, their tail as values, and their kernel,
+epsilon, smoothing and tail type as params.
+
+UncertainInterpolation is not serialized, since it depends
+on the function that built its spline: store its data
+(with Errs) and rebuild it with UncertainSpline().
+
+Decoding accepts any version up to serialVersion, so stored
+models survive library upgrades; newer versions are rejected
+with ErrVersion.
+*/
+
+const serialVersion = 1
+
+//ErrFormat - malformed serialized data
+var ErrFormat = errors.New("vec: malformed serialized data")
+
+//ErrVersion - serialized data from a newer version of this package
+var ErrVersion = errors.New("vec: unsupported serialization version")
+
+var serialMagic = []byte("VEC")
+
+//record types (the binary type byte is the index in this list)
+var serialTypes = []string{"", "data", "cubic", "linear", "nearest", "step", "barycentric", "bspline", "grid", "ndgrid", "rbf", "quintic", "antiderivative", "parametric", "streaming"}
+
+//common on-the-wire form of every serializable type
+type record struct {
+	Version       int           `json:"version"`
+	Type          string        `json:"type"`
+	Extrapolation Extrapolation `json:"extrapolation,omitempty"`
+	Degree        int           `json:"degree,omitempty"`
+	Xs            []float64     `json:"xs,omitempty"`
+	Ys            []float64     `json:"ys,omitempty"`
+	Coeffs        []float64     `json:"coeffs,omitempty"`
+	Knots         []float64     `json:"knots,omitempty"`
+	Errs          nullFloats    `json:"errs,omitempty"`
+	Axes          [][]float64   `json:"axes,omitempty"`
+	Values        []float64     `json:"values,omitempty"`
+	Points        [][]float64   `json:"points,omitempty"`
+	Params        []float64     `json:"params,omitempty"`
+}
+
+//float64s whose NaNs are written as JSON null
+type nullFloats []float64
+
+func (f nullFloats) MarshalJSON() ([]byte, error) {
+	out := make([]*float64, len(f))
+	for i := range f {
+		if !math.IsNaN(f[i]) {
+			out[i] = &f[i]
+		}
+	}
+	return json.Marshal(out)
+}
+
+func (f *nullFloats) UnmarshalJSON(data []byte) error {
+	var in []*float64
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if in == nil {
+		*f = nil
+		return nil
+	}
+	*f = make(nullFloats, len(in))
+	for i, p := range in {
+		(*f)[i] = math.NaN()
+		if p != nil {
+			(*f)[i] = *p
+		}
+	}
+	return nil
+}
+
+func (r *record) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	typ := 0
+	for i, t := range serialTypes {
+		if t == r.Type {
+			typ = i
+		}
+	}
+	buf.Write(serialMagic)
+	buf.WriteByte(serialVersion)
+	buf.WriteByte(byte(typ))
+	binary.Write(&buf, binary.LittleEndian, int32(r.Extrapolation))
+	binary.Write(&buf, binary.LittleEndian, int32(r.Degree))
+	for _, f := range [][]float64{r.Xs, r.Ys, r.Coeffs, r.Knots, []float64(r.Errs)} {
+		writeFloats(&buf, f)
+	}
+	writeLists(&buf, r.Axes)
+	writeFloats(&buf, r.Values)
+	writeLists(&buf, r.Points)
+	writeFloats(&buf, r.Params)
+	return buf.Bytes(), nil
+}
+
+func writeFloats(buf *bytes.Buffer, f []float64) {
+	binary.Write(buf, binary.LittleEndian, uint32(len(f)))
+	binary.Write(buf, binary.LittleEndian, f)
+}
+
+func writeLists(buf *bytes.Buffer, l [][]float64) {
+	binary.Write(buf, binary.LittleEndian, uint32(len(l)))
+	for _, f := range l {
+		writeFloats(buf, f)
+	}
+}
+
+//reads a count and that many float64s; false if the data runs out
+func readFloats(rd *bytes.Reader, f *[]float64) bool {
+	var n uint32
+	if binary.Read(rd, binary.LittleEndian, &n) != nil || int(n) > rd.Len()/8 {
+		return false
+	}
+	*f = make([]float64, n)
+	return binary.Read(rd, binary.LittleEndian, *f) == nil
+}
+
+//reads a count and that many float64 lists
+func readLists(rd *bytes.Reader, l *[][]float64) bool {
+	var n uint32
+	if binary.Read(rd, binary.LittleEndian, &n) != nil || int(n) > rd.Len()/4 {
+		return false
+	}
+	*l = make([][]float64, n)
+	for i := range *l {
+		if !readFloats(rd, &(*l)[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *record) UnmarshalBinary(data []byte) error {
+	if len(data) < 5 || !bytes.Equal(data[:3], serialMagic) {
+		return ErrFormat
+	}
+	r.Version = int(data[3])
+	if r.Version > serialVersion {
+		return ErrVersion
+	}
+	if int(data[4]) >= len(serialTypes) {
+		return ErrFormat
+	}
+	r.Type = serialTypes[data[4]]
+
+	rd := bytes.NewReader(data[5:])
+	var ext, deg int32
+	if binary.Read(rd, binary.LittleEndian, &ext) != nil || binary.Read(rd, binary.LittleEndian, &deg) != nil {
+		return ErrFormat
+	}
+	r.Extrapolation, r.Degree = Extrapolation(ext), int(deg)
+	for _, f := range []*[]float64{&r.Xs, &r.Ys, &r.Coeffs, &r.Knots, (*[]float64)(&r.Errs)} {
+		if !readFloats(rd, f) {
+			return ErrFormat
+		}
+	}
+	if !readLists(rd, &r.Axes) || !readFloats(rd, &r.Values) || !readLists(rd, &r.Points) || !readFloats(rd, &r.Params) {
+		return ErrFormat
+	}
+	if rd.Len() != 0 {
+		return ErrFormat
+	}
+	return nil
+}
+
+//decodes JSON or binary into a record of type 'typ'
+func decodeRecord(data []byte, isJSON bool, typ string) (*record, error) {
+	r := &record{}
+	if isJSON {
+		if err := json.Unmarshal(data, r); err != nil {
+			return nil, err
+		}
+		if r.Version > serialVersion {
+			return nil, ErrVersion
+		}
+	} else if err := r.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if r.Type != typ || r.Version < 1 {
+		return nil, ErrFormat
+	}
+	return r, nil
+}
+
+func newRecord(typ string) *record {
+	return &record{Version: serialVersion, Type: typ}
+}
+
+//rebuilds BiVariateData from a record, checking lengths and finiteness
+func (r *record) data() (*BiVariateData, error) {
+	if len(r.Xs) != len(r.Ys) {
+		return nil, ErrFormat
+	}
+	for i := range r.Xs {
+		if notRat(r.Xs[i]) {
+			return nil, ErrFormat
+		}
+	}
+	d := &BiVariateData{Xs: r.Xs, Ys: r.Ys}
+	if len(r.Errs) > 0 {
+		if len(r.Errs) != len(r.Xs) {
+			return nil, ErrFormat
+		}
+		d.Errs = r.Errs
+	}
+	d.isSorted = sort.Float64sAreSorted(d.Xs)
+	return d, nil
+}
+
+//records for interpolations need at least two points
+func (r *record) interpData() (*BiVariateData, error) {
+	d, err := r.data()
+	if err == nil && (len(d.Xs) < 2 || !d.isSorted) {
+		err = ErrFormat
+	}
+	return d, err
+}
+
+//Implements encoding.BinaryMarshaler
+func (b *BiVariateData) MarshalBinary() ([]byte, error) {
+	return b.record().MarshalBinary()
+}
+
+//Implements json.Marshaler
+func (b *BiVariateData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.record())
+}
+
+//Implements encoding.BinaryUnmarshaler
+func (b *BiVariateData) UnmarshalBinary(data []byte) error {
+	return b.fromRecord(decodeRecord(data, false, "data"))
+}
+
+//Implements json.Unmarshaler
+func (b *BiVariateData) UnmarshalJSON(data []byte) error {
+	return b.fromRecord(decodeRecord(data, true, "data"))
+}
+
+func (b *BiVariateData) record() *record {
+	r := newRecord("data")
+	r.Xs, r.Ys, r.Errs = b.Xs, b.Ys, b.Errs
+	return r
+}
+
+func (b *BiVariateData) fromRecord(r *record, err error) error {
+	if err != nil {
+		return err
+	}
+	d, err := r.data()
+	if err != nil {
+		return err
+	}
+	*b = *d
+	return nil
+}
+
+//Implements encoding.BinaryMarshaler
+func (s *CubicSplineInterpolation) MarshalBinary() ([]byte, error) {
+	return s.record().MarshalBinary()
+}
+
+//Implements json.Marshaler
+func (s *CubicSplineInterpolation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.record())
+}
+
+//Implements encoding.BinaryUnmarshaler
+func (s *CubicSplineInterpolation) UnmarshalBinary(data []byte) error {
+	return s.fromRecord(decodeRecord(data, false, "cubic"))
+}
+
+//Implements json.Unmarshaler
+func (s *CubicSplineInterpolation) UnmarshalJSON(data []byte) error {
+	return s.fromRecord(decodeRecord(data, true, "cubic"))
+}
+
+func (s *CubicSplineInterpolation) record() *record {
+	r := newRecord("cubic")
+	r.Xs, r.Ys, r.Coeffs, r.Extrapolation = s.data.Xs, s.data.Ys, s.coeffs, s.ext
+	return r
+}
+
+func (s *CubicSplineInterpolation) fromRecord(r *record, err error) error {
+	if err != nil {
+		return err
+	}
+	d, err := r.interpData()
+	if err != nil {
+		return err
+	}
+	if len(r.Coeffs) != len(d.Xs) {
+		return ErrFormat
+	}
+	*s = CubicSplineInterpolation{data: d, coeffs: r.Coeffs, ext: r.Extrapolation}
+	return nil
+}
+
+//Implements encoding.BinaryMarshaler
+func (l *LinearInterpolation) MarshalBinary() ([]byte, error) {
+	return dataRecord("linear", l.data, l.ext).MarshalBinary()
+}
+
+//Implements json.Marshaler
+func (l *LinearInterpolation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dataRecord("linear", l.data, l.ext))
+}
+
+//Implements encoding.BinaryUnmarshaler
+func (l *LinearInterpolation) UnmarshalBinary(data []byte) error {
+	return fromDataRecord(decodeRecord(data, false, "linear"))(&l.data, &l.ext)
+}
+
+//Implements json.Unmarshaler
+func (l *LinearInterpolation) UnmarshalJSON(data []byte) error {
+	return fromDataRecord(decodeRecord(data, true, "linear"))(&l.data, &l.ext)
+}
+
+//Implements encoding.BinaryMarshaler
+func (n *NearestInterpolation) MarshalBinary() ([]byte, error) {
+	return dataRecord("nearest", n.data, n.ext).MarshalBinary()
+}
+
+//Implements json.Marshaler
+func (n *NearestInterpolation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dataRecord("nearest", n.data, n.ext))
+}
+
+//Implements encoding.BinaryUnmarshaler
+func (n *NearestInterpolation) UnmarshalBinary(data []byte) error {
+	return fromDataRecord(decodeRecord(data, false, "nearest"))(&n.data, &n.ext)
+}
+
+//Implements json.Unmarshaler
+func (n *NearestInterpolation) UnmarshalJSON(data []byte) error {
+	return fromDataRecord(decodeRecord(data, true, "nearest"))(&n.data, &n.ext)
+}
+
+//Implements encoding.BinaryMarshaler
+func (s *StepInterpolation) MarshalBinary() ([]byte, error) {
+	return dataRecord("step", s.data, s.ext).MarshalBinary()
+}
+
+//Implements json.Marshaler
+func (s *StepInterpolation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dataRecord("step", s.data, s.ext))
+}
+
+//Implements encoding.BinaryUnmarshaler
+func (s *StepInterpolation) UnmarshalBinary(data []byte) error {
+	return fromDataRecord(decodeRecord(data, false, "step"))(&s.data, &s.ext)
+}
+
+//Implements json.Unmarshaler
+func (s *StepInterpolation) UnmarshalJSON(data []byte) error {
+	return fromDataRecord(decodeRecord(data, true, "step"))(&s.data, &s.ext)
+}
+
+//record for the interpolations that are fully described by their data
+func dataRecord(typ string, d *BiVariateData, ext Extrapolation) *record {
+	r := newRecord(typ)
+	r.Xs, r.Ys, r.Extrapolation = d.Xs, d.Ys, ext
+	return r
+}
+
+//returns a setter for the fields of a decoded data-only interpolation
+func fromDataRecord(r *record, err error) func(**BiVariateData, *Extrapolation) error {
+	return func(d **BiVariateData, ext *Extrapolation) error {
+		if err != nil {
+			return err
+		}
+		bvd, err := r.interpData()
+		if err != nil {
+			return err
+		}
+		*d, *ext = bvd, r.Extrapolation
+		return nil
+	}
+}
+
+//Implements encoding.BinaryMarshaler
+func (p *BarycentricInterpolation) MarshalBinary() ([]byte, error) {
+	return p.record().MarshalBinary()
+}
+
+//Implements json.Marshaler
+func (p *BarycentricInterpolation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.record())
+}
+
+//Implements encoding.BinaryUnmarshaler
+func (p *BarycentricInterpolation) UnmarshalBinary(data []byte) error {
+	return p.fromRecord(decodeRecord(data, false, "barycentric"))
+}
+
+//Implements json.Unmarshaler
+func (p *BarycentricInterpolation) UnmarshalJSON(data []byte) error {
+	return p.fromRecord(decodeRecord(data, true, "barycentric"))
+}
+
+func (p *BarycentricInterpolation) record() *record {
+	r := dataRecord("barycentric", p.data, p.ext)
+	r.Coeffs = p.ws
+	return r
+}
+
+func (p *BarycentricInterpolation) fromRecord(r *record, err error) error {
+	if err != nil {
+		return err
+	}
+	d, err := r.data()
+	if err != nil {
+		return err
+	}
+	if len(d.Xs) == 0 || !d.isSorted || len(r.Coeffs) != len(d.Xs) {
+		return ErrFormat
+	}
+	*p = BarycentricInterpolation{data: d, ws: r.Coeffs, ext: r.Extrapolation}
+	return nil
+}
+
+//Implements encoding.BinaryMarshaler
+func (b *BSpline) MarshalBinary() ([]byte, error) {
+	return b.record().MarshalBinary()
+}
+
+//Implements json.Marshaler
+func (b *BSpline) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.record())
+}
+
+//Implements encoding.BinaryUnmarshaler
+func (b *BSpline) UnmarshalBinary(data []byte) error {
+	return b.fromRecord(decodeRecord(data, false, "bspline"))
+}
+
+//Implements json.Unmarshaler
+func (b *BSpline) UnmarshalJSON(data []byte) error {
+	return b.fromRecord(decodeRecord(data, true, "bspline"))
+}
+
+func (b *BSpline) record() *record {
+	r := newRecord("bspline")
+	r.Knots, r.Coeffs, r.Degree = b.Knots, b.Coeffs, b.Degree
+	return r
+}
+
+func (b *BSpline) fromRecord(r *record, err error) error {
+	if err != nil {
+		return err
+	}
+	n := len(r.Knots) - r.Degree - 1
+	if r.Degree < 0 || n < r.Degree+1 || len(r.Coeffs) != n || !sort.Float64sAreSorted(r.Knots) {
+		return ErrFormat
+	}
+	for _, t := range r.Knots {
+		if math.IsNaN(t) {
+			return ErrFormat
+		}
+	}
+	*b = BSpline{Knots: r.Knots, Coeffs: r.Coeffs, Degree: r.Degree}
+	return nil
+}
+
+//Implements encoding.BinaryMarshaler
+func (gi *GridInterpolation) MarshalBinary() ([]byte, error) {
+	return gi.record().MarshalBinary()
+}
+
+//Implements json.Marshaler
+func (gi *GridInterpolation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gi.record())
+}
+
+//Implements encoding.BinaryUnmarshaler
+func (gi *GridInterpolation) UnmarshalBinary(data []byte) error {
+	return gi.fromRecord(decodeRecord(data, false, "grid"))
+}
+
+//Implements json.Unmarshaler
+func (gi *GridInterpolation) UnmarshalJSON(data []byte) error {
+	return gi.fromRecord(decodeRecord(data, true, "grid"))
+}
+
+func (gi *GridInterpolation) record() *record {
+	r := newRecord("grid")
+	g := gi.grid
+	r.Axes = [][]float64{g.Xs, g.Ys}
+	for _, row := range g.Zs {
+		r.Values = append(r.Values, row...)
+	}
+	r.Degree = 1
+	if gi.cubic {
+		r.Degree = 3
+	}
+	return r
+}
+
+func (gi *GridInterpolation) fromRecord(r *record, err error) error {
+	if err != nil {
+		return err
+	}
+	if len(r.Axes) != 2 || len(r.Values) != len(r.Axes[0])*len(r.Axes[1]) {
+		return ErrFormat
+	}
+	g := &Grid{Xs: r.Axes[0], Ys: r.Axes[1], Zs: make([][]float64, len(r.Axes[0]))}
+	for i := range g.Zs {
+		g.Zs[i] = r.Values[i*len(g.Ys) : (i+1)*len(g.Ys)]
+	}
+	var out *GridInterpolation
+	switch r.Degree {
+	case 1:
+		out, err = Bilinear(g)
+	case 3:
+		out, err = Bicubic(g)
+	default:
+		return ErrFormat
+	}
+	if err != nil {
+		return ErrFormat
+	}
+	*gi = *out
+	return nil
+}
+
+//Implements encoding.BinaryMarshaler
+func (n *NdInterpolation) MarshalBinary() ([]byte, error) {
+	return n.record().MarshalBinary()
+}
+
+//Implements json.Marshaler
+func (n *NdInterpolation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.record())
+}
+
+//Implements encoding.BinaryUnmarshaler
+func (n *NdInterpolation) UnmarshalBinary(data []byte) error {
+	return n.fromRecord(decodeRecord(data, false, "ndgrid"))
+}
+
+//Implements json.Unmarshaler
+func (n *NdInterpolation) UnmarshalJSON(data []byte) error {
+	return n.fromRecord(decodeRecord(data, true, "ndgrid"))
+}
+
+func (n *NdInterpolation) record() *record {
+	r := newRecord("ndgrid")
+	r.Axes, r.Values = n.grid.Axes, n.grid.Values
+	r.Degree = 1
+	if n.cubic {
+		r.Degree = 3
+	}
+	return r
+}
+
+func (n *NdInterpolation) fromRecord(r *record, err error) error {
+	if err != nil {
+		return err
+	}
+	if r.Degree != 1 && r.Degree != 3 {
+		return ErrFormat
+	}
+	out, err := newNdInterpolation(&NdGrid{Axes: r.Axes, Values: r.Values}, r.Degree == 3)
+	if err != nil {
+		return ErrFormat
+	}
+	*n = *out
+	return nil
+}
+
+//Implements encoding.BinaryMarshaler
+func (rb *RBFInterpolation) MarshalBinary() ([]byte, error) {
+	return rb.record().MarshalBinary()
+}
+
+//Implements json.Marshaler
+func (rb *RBFInterpolation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rb.record())
+}
+
+//Implements encoding.BinaryUnmarshaler
+func (rb *RBFInterpolation) UnmarshalBinary(data []byte) error {
+	return rb.fromRecord(decodeRecord(data, false, "rbf"))
+}
+
+//Implements json.Unmarshaler
+func (rb *RBFInterpolation) UnmarshalJSON(data []byte) error {
+	return rb.fromRecord(decodeRecord(data, true, "rbf"))
+}
+
+func (rb *RBFInterpolation) record() *record {
+	r := newRecord("rbf")
+	o := rb.opts
+	r.Points, r.Coeffs, r.Values = rb.centers, rb.coeffs, rb.tail
+	r.Params = []float64{float64(o.Kernel), o.Epsilon, o.Smoothing, float64(o.Tail)}
+	return r
+}
+
+func (rb *RBFInterpolation) fromRecord(r *record, err error) error {
+	if err != nil {
+		return err
+	}
+	N := len(r.Points)
+	if N == 0 || len(r.Coeffs) != N || len(r.Params) != 4 {
+		return ErrFormat
+	}
+	D := len(r.Points[0])
+	for _, p := range r.Points {
+		if len(p) != D {
+			return ErrFormat
+		}
+	}
+	o := RBFOptions{Kernel: RBFKernel(r.Params[0]), Epsilon: r.Params[1], Smoothing: r.Params[2], Tail: RBFTail(r.Params[3])}
+	M := 0
+	switch o.Tail {
+	case TailConstant:
+		M = 1
+	case TailLinear:
+		M = 1 + D
+	}
+	if o.Kernel < RBFGaussian || o.Kernel > RBFCubic || o.Tail < TailNone || o.Tail > TailLinear || len(r.Values) != M {
+		return ErrFormat
+	}
+	*rb = RBFInterpolation{centers: r.Points, coeffs: r.Coeffs, tail: r.Values, opts: o}
+	return nil
+}
+
+//Implements encoding.BinaryMarshaler
+func (q *QuinticHermiteInterpolation) MarshalBinary() ([]byte, error) {
+	return q.record().MarshalBinary()
+}
+
+//Implements json.Marshaler
+func (q *QuinticHermiteInterpolation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.record())
+}
+
+//Implements encoding.BinaryUnmarshaler
+func (q *QuinticHermiteInterpolation) UnmarshalBinary(data []byte) error {
+	return q.fromRecord(decodeRecord(data, false, "quintic"))
+}
+
+//Implements json.Unmarshaler
+func (q *QuinticHermiteInterpolation) UnmarshalJSON(data []byte) error {
+	return q.fromRecord(decodeRecord(data, true, "quintic"))
+}
+
+func (q *QuinticHermiteInterpolation) record() *record {
+	r := dataRecord("quintic", q.data, q.ext)
+	r.Coeffs, r.Values = q.dys, q.ddys
+	return r
+}
+
+func (q *QuinticHermiteInterpolation) fromRecord(r *record, err error) error {
+	if err != nil {
+		return err
+	}
+	d, err := r.interpData()
+	if err != nil {
+		return err
+	}
+	if len(r.Coeffs) != len(d.Xs) || len(r.Values) != len(d.Xs) {
+		return ErrFormat
+	}
+	*q = QuinticHermiteInterpolation{data: d, dys: r.Coeffs, ddys: r.Values, ext: r.Extrapolation}
+	return nil
+}
+
+//Implements encoding.BinaryMarshaler
+func (q *SplineAntiderivative) MarshalBinary() ([]byte, error) {
+	return q.record().MarshalBinary()
+}
+
+//Implements json.Marshaler
+func (q *SplineAntiderivative) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.record())
+}
+
+//Implements encoding.BinaryUnmarshaler
+func (q *SplineAntiderivative) UnmarshalBinary(data []byte) error {
+	return q.fromRecord(decodeRecord(data, false, "antiderivative"))
+}
+
+//Implements json.Unmarshaler
+func (q *SplineAntiderivative) UnmarshalJSON(data []byte) error {
+	return q.fromRecord(decodeRecord(data, true, "antiderivative"))
+}
+
+//the spline itself; the running integrals are rebuilt on decoding
+func (q *SplineAntiderivative) record() *record {
+	r := dataRecord("antiderivative", q.spline.data, q.ext)
+	r.Coeffs = q.spline.coeffs
+	return r
+}
+
+func (q *SplineAntiderivative) fromRecord(r *record, err error) error {
+	if err != nil {
+		return err
+	}
+	s := &CubicSplineInterpolation{}
+	if err := s.fromRecord(r, nil); err != nil {
+		return err
+	}
+	out, err := s.Antiderivative()
+	if err != nil {
+		return ErrFormat
+	}
+	*q = *out
+	return nil
+}
+
+//Implements encoding.BinaryMarshaler
+func (p *ParametricSpline) MarshalBinary() ([]byte, error) {
+	return p.record().MarshalBinary()
+}
+
+//Implements json.Marshaler
+func (p *ParametricSpline) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.record())
+}
+
+//Implements encoding.BinaryUnmarshaler
+func (p *ParametricSpline) UnmarshalBinary(data []byte) error {
+	return p.fromRecord(decodeRecord(data, false, "parametric"))
+}
+
+//Implements json.Unmarshaler
+func (p *ParametricSpline) UnmarshalJSON(data []byte) error {
+	return p.fromRecord(decodeRecord(data, true, "parametric"))
+}
+
+/*
+the knot parameters as xs and each coordinate as a point
+list; params are {closed, by arc length} (0 or 1), and the
+splines are rebuilt on decoding
+*/
+func (p *ParametricSpline) record() *record {
+	r := newRecord("parametric")
+	r.Xs = p.ts
+	for _, c := range p.coords {
+		r.Points = append(r.Points, c.data.Ys)
+	}
+	r.Params = []float64{0, 0}
+	if p.closed {
+		r.Params[0] = 1
+	}
+	if p.toT != nil {
+		r.Params[1] = 1
+	}
+	return r
+}
+
+func (p *ParametricSpline) fromRecord(r *record, err error) error {
+	if err != nil {
+		return err
+	}
+	N := len(r.Xs)
+	if N < 2 || len(r.Points) == 0 || len(r.Params) != 2 || checkAxis(r.Xs) != nil {
+		return ErrFormat
+	}
+	out := &ParametricSpline{ts: r.Xs, coords: make([]*CubicSplineInterpolation, len(r.Points)), closed: r.Params[0] == 1}
+	ends := SplineEnds{Cond: Natural}
+	if out.closed {
+		ends.Cond = Periodic
+	}
+	for k, ys := range r.Points {
+		if len(ys) != N {
+			return ErrFormat
+		}
+		if out.coords[k], err = CubicSplineEnds(&BiVariateData{Xs: r.Xs, Ys: ys, isSorted: true}, ends); err != nil {
+			return ErrFormat
+		}
+	}
+	if r.Params[1] == 1 {
+		out = out.ByArcLength()
+	}
+	*p = *out
+	return nil
+}
+
+//Implements encoding.BinaryMarshaler
+func (s *StreamingInterpolation) MarshalBinary() ([]byte, error) {
+	return s.record().MarshalBinary()
+}
+
+//Implements json.Marshaler
+func (s *StreamingInterpolation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.record())
+}
+
+//Implements encoding.BinaryUnmarshaler
+func (s *StreamingInterpolation) UnmarshalBinary(data []byte) error {
+	return s.fromRecord(decodeRecord(data, false, "streaming"))
+}
+
+//Implements json.Unmarshaler
+func (s *StreamingInterpolation) UnmarshalJSON(data []byte) error {
+	return s.fromRecord(decodeRecord(data, true, "streaming"))
+}
+
+//the method as the degree, the knot slopes as values, and the window as a param
+func (s *StreamingInterpolation) record() *record {
+	r := dataRecord("streaming", s.data, s.ext)
+	r.Coeffs, r.Values = s.coeffs, s.m
+	r.Degree = int(s.method)
+	r.Params = []float64{float64(s.window)}
+	return r
+}
+
+func (s *StreamingInterpolation) fromRecord(r *record, err error) error {
+	if err != nil {
+		return err
+	}
+	d, err := r.data()
+	if err != nil {
+		return err
+	}
+	N := len(d.Xs)
+	if checkAxis(d.Xs) != nil || len(r.Coeffs) != N || len(r.Values) != N || len(r.Params) != 1 {
+		return ErrFormat
+	}
+	if r.Degree < int(StreamMonotone) || r.Degree > int(StreamSteffen) || r.Params[0] < 0 || (r.Params[0] > 0 && float64(N) > r.Params[0]) {
+		return ErrFormat
+	}
+	out := StreamingSpline(StreamMethod(r.Degree), int(r.Params[0]))
+	out.data, out.coeffs, out.m, out.ext = d, r.Coeffs, r.Values, r.Extrapolation
+	*s = *out
+	return nil
+}
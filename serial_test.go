@@ -0,0 +1,303 @@
+package vec
+
+import "testing"
+import "math"
+
+/*
+Test binary and JSON round trips of the 1-D interpolations
+
+- every type decodes to the same values and extrapolation
+*/
+func TestSerialRoundTrip(t *testing.T) {
+	xs := []float64{0, 0.5, 1.5, 2, 3.25, 4}
+	ys := []float64{1, -1, 2, 0.5, 3, 2}
+	d := MakeBiVariateData(xs, ys)
+	gx := Arange(0, 4, 17)
+	b, _ := FitBSpline(MakeBiVariateData(gx, Arange(0, 4, 17)), nil, ClampedKnots(0, 4, []float64{1, 2.5}, 3), 3)
+	its := []Interpolator{
+		CubicSpline(d).Extrapolate(ExtrapolateLinear),
+		LinearInterp(d).Extrapolate(ExtrapolateClamp),
+		NearestInterp(d),
+		StepInterp(d).Extrapolate(ExtrapolateNaN),
+		Barycentric(d),
+		b,
+	}
+	for _, it := range its {
+		bin, err := it.(interface{ MarshalBinary() ([]byte, error) }).MarshalBinary()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		js, err := it.(interface{ MarshalJSON() ([]byte, error) }).MarshalJSON()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		var fromBin, fromJS Interpolator
+		switch it.(type) {
+		case *CubicSplineInterpolation:
+			u, v := &CubicSplineInterpolation{}, &CubicSplineInterpolation{}
+			err = firstErr(u.UnmarshalBinary(bin), v.UnmarshalJSON(js))
+			fromBin, fromJS = u, v
+		case *LinearInterpolation:
+			u, v := &LinearInterpolation{}, &LinearInterpolation{}
+			err = firstErr(u.UnmarshalBinary(bin), v.UnmarshalJSON(js))
+			fromBin, fromJS = u, v
+		case *NearestInterpolation:
+			u, v := &NearestInterpolation{}, &NearestInterpolation{}
+			err = firstErr(u.UnmarshalBinary(bin), v.UnmarshalJSON(js))
+			fromBin, fromJS = u, v
+		case *StepInterpolation:
+			u, v := &StepInterpolation{}, &StepInterpolation{}
+			err = firstErr(u.UnmarshalBinary(bin), v.UnmarshalJSON(js))
+			fromBin, fromJS = u, v
+		case *BarycentricInterpolation:
+			u, v := &BarycentricInterpolation{}, &BarycentricInterpolation{}
+			err = firstErr(u.UnmarshalBinary(bin), v.UnmarshalJSON(js))
+			fromBin, fromJS = u, v
+		case *BSpline:
+			u, v := &BSpline{}, &BSpline{}
+			err = firstErr(u.UnmarshalBinary(bin), v.UnmarshalJSON(js))
+			fromBin, fromJS = u, v
+		}
+		if err != nil {
+			t.Error("Decoding failed for", it, "Got:", err)
+			continue
+		}
+		for _, x := range []float64{-1, 0, 0.3, 1.7, 3.9, 5} {
+			want := it.F(x)
+			if !areSimilar(fromBin.F(x), want) && !(want != want && fromBin.F(x) != fromBin.F(x)) {
+				t.Error("Binary round trip changed F at", x, "Got:", fromBin.F(x), "Expected:", want)
+			}
+			if !areSimilar(fromJS.F(x), want) && !(want != want && fromJS.F(x) != fromJS.F(x)) {
+				t.Error("JSON round trip changed F at", x, "Got:", fromJS.F(x), "Expected:", want)
+			}
+		}
+	}
+}
+
+//first non-nil error of 'errs'
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+Test round trips of the grid and scattered-data interpolations
+
+- bilinear, bicubic, N-d and RBF interpolations decode to
+the same values from binary and JSON
+
+EDGE CASES:
+- a grid whose values don't match its axes is rejected
+*/
+func TestSerialGrids(t *testing.T) {
+	f := func(x float64, y float64) float64 {
+		return x*x - 2*x*y + 3*y*y + x - 1
+	}
+	g := makeGrid(f)
+	bl, _ := Bilinear(g)
+	bc, _ := Bicubic(g)
+	for _, gi := range []*GridInterpolation{bl, bc} {
+		bin, _ := gi.MarshalBinary()
+		js, _ := gi.MarshalJSON()
+		u, v := &GridInterpolation{}, &GridInterpolation{}
+		if err := firstErr(u.UnmarshalBinary(bin), v.UnmarshalJSON(js)); err != nil {
+			t.Error("Grid decoding failed. Got:", err)
+			continue
+		}
+		for _, p := range [][2]float64{{0.2, -0.3}, {1.7, 0.1}, {3.5, -1.5}} {
+			want := gi.F(p[0], p[1])
+			if u.F(p[0], p[1]) != want || v.F(p[0], p[1]) != want {
+				t.Error("Grid round trip changed F at", p, "Got:", u.F(p[0], p[1]), v.F(p[0], p[1]), "Expected:", want)
+			}
+		}
+	}
+	bad := &GridInterpolation{}
+	if bad.UnmarshalJSON([]byte(`{"version":1,"type":"grid","degree":1,"axes":[[0,1],[0,1]],"values":[1,2,3]}`)) != ErrFormat {
+		t.Error("Expected ErrFormat for a grid with too few values.")
+	}
+
+	nf := func(p []float64) float64 {
+		return p[0]*p[0] + p[1]*p[2] - p[2]*p[2]
+	}
+	nl, _ := NdLinear(makeNdGrid(nf))
+	nc, _ := NdCubic(makeNdGrid(nf))
+	for _, n := range []*NdInterpolation{nl, nc} {
+		bin, _ := n.MarshalBinary()
+		js, _ := n.MarshalJSON()
+		u, v := &NdInterpolation{}, &NdInterpolation{}
+		if err := firstErr(u.UnmarshalBinary(bin), v.UnmarshalJSON(js)); err != nil {
+			t.Error("NdGrid decoding failed. Got:", err)
+			continue
+		}
+		for _, p := range ndPts {
+			if u.F(p) != n.F(p) || v.F(p) != n.F(p) {
+				t.Error("NdGrid round trip changed F at", p, "Got:", u.F(p), v.F(p), "Expected:", n.F(p))
+			}
+		}
+	}
+
+	pts := [][]float64{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0.5, 0.3}, {0.2, 0.8}}
+	vals := make([]float64, len(pts))
+	for i, p := range pts {
+		vals[i] = math.Sin(p[0]) + p[1]
+	}
+	r, _ := RBF(pts, vals, RBFOptions{Kernel: RBFThinPlate, Tail: TailLinear, Smoothing: 1E-3})
+	bin, _ := r.MarshalBinary()
+	js, _ := r.MarshalJSON()
+	u, v := &RBFInterpolation{}, &RBFInterpolation{}
+	if err := firstErr(u.UnmarshalBinary(bin), v.UnmarshalJSON(js)); err != nil {
+		t.Fatal("RBF decoding failed. Got:", err)
+	}
+	for _, p := range [][]float64{{0.3, 0.4}, {0.9, 0.1}, {1.5, -0.5}} {
+		if !areSimilar(u.F(p), r.F(p)) || !areSimilar(v.F(p), r.F(p)) {
+			t.Error("RBF round trip changed F at", p, "Got:", u.F(p), v.F(p), "Expected:", r.F(p))
+		}
+	}
+}
+
+/*
+Test round trips of BiVariateData and version handling
+
+- standard errors are kept, and optional in JSON
+- unknown (NaN) standard errors survive JSON as null
+
+EDGE CASES:
+- newer versions return ErrVersion
+- truncated data and mismatched types return ErrFormat
+*/
+func TestSerialData(t *testing.T) {
+	d := MakeBiVariateData([]float64{3, 1, 2}, []float64{9, 1, 4})
+	bin, _ := d.MarshalBinary()
+	e := &BiVariateData{}
+	if err := e.UnmarshalBinary(bin); err != nil {
+		t.Fatal(err)
+	}
+	for i := range d.Xs {
+		if e.Xs[i] != d.Xs[i] || e.Ys[i] != d.Ys[i] {
+			t.Error("Data did not survive a binary round trip. Got:", e.Xs, e.Ys, "Expected:", d.Xs, d.Ys)
+		}
+	}
+
+	d.Errs = []float64{0.3, 0.1, 0.2}
+	js, _ := d.MarshalJSON()
+	if err := e.UnmarshalJSON(js); err != nil || len(e.Errs) != 3 || e.Errs[2] != 0.2 {
+		t.Error("Standard errors did not survive a JSON round trip. Got:", e.Errs, err)
+	}
+	d.Errs[1] = math.NaN()
+	js, err := d.MarshalJSON()
+	if err != nil {
+		t.Fatal("JSON encoding failed with a NaN error. Got:", err)
+	}
+	if err := e.UnmarshalJSON(js); err != nil || !math.IsNaN(e.Errs[1]) || e.Errs[2] != 0.2 {
+		t.Error("Unknown standard errors did not survive a JSON round trip. Got:", string(js), e.Errs, err)
+	}
+	if err := e.UnmarshalJSON([]byte(`{"version":1,"type":"data","xs":[1,2],"ys":[3,4]}`)); err != nil || e.Errs != nil {
+		t.Error("Expected nil standard errors when none are stored. Got:", e.Errs, err)
+	}
+
+	bad := append([]byte{}, bin...)
+	bad[3] = serialVersion + 1
+	if err := e.UnmarshalBinary(bad); err != ErrVersion {
+		t.Error("Expected ErrVersion for a newer version. Got:", err)
+	}
+	if err := e.UnmarshalBinary(bin[:len(bin)-4]); err != ErrFormat {
+		t.Error("Expected ErrFormat for truncated data. Got:", err)
+	}
+	if err := e.UnmarshalJSON([]byte(`{"version":99,"type":"data"}`)); err != ErrVersion {
+		t.Error("Expected ErrVersion for a newer JSON version. Got:", err)
+	}
+	s := &CubicSplineInterpolation{}
+	if err := s.UnmarshalBinary(bin); err != ErrFormat {
+		t.Error("Expected ErrFormat when decoding data as a spline. Got:", err)
+	}
+}
+
+/*
+Test round trips of the remaining 1-D and parametric types
+
+- quintic Hermite, antiderivative, parametric (closed and
+by arc length) and streaming splines decode to the same
+values from binary and JSON
+- a decoded streaming spline keeps accepting points
+
+EDGE CASES:
+- a parametric record with a coordinate of the wrong length is rejected
+*/
+func TestSerialCurves(t *testing.T) {
+	xs := []float64{0, 0.5, 1.5, 2, 3.25, 4}
+	ys := []float64{1, -1, 2, 0.5, 3, 2}
+	q, _ := QuinticHermite(xs, ys, []float64{0, 1, 0, -1, 0, 1}, []float64{1, 0, -1, 0, 1, 0})
+	a, _ := CubicSpline(MakeBiVariateData(append([]float64{}, xs...), append([]float64{}, ys...))).Antiderivative()
+	a = a.Extrapolate(ExtrapolateLinear)
+	type roundTrip struct {
+		it, bin, js Interpolator
+		bu, ju      func([]byte) error
+	}
+	qb, qj := &QuinticHermiteInterpolation{}, &QuinticHermiteInterpolation{}
+	ab, aj := &SplineAntiderivative{}, &SplineAntiderivative{}
+	pairs := []roundTrip{
+		{q, qb, qj, qb.UnmarshalBinary, qj.UnmarshalJSON},
+		{a, ab, aj, ab.UnmarshalBinary, aj.UnmarshalJSON},
+	}
+	for _, p := range pairs {
+		bin, _ := p.it.(interface{ MarshalBinary() ([]byte, error) }).MarshalBinary()
+		js, _ := p.it.(interface{ MarshalJSON() ([]byte, error) }).MarshalJSON()
+		if err := firstErr(p.bu(bin), p.ju(js)); err != nil {
+			t.Error("Decoding failed for", p.it, "Got:", err)
+			continue
+		}
+		for _, x := range []float64{-1, 0.3, 1.7, 3.9, 5} {
+			if p.bin.F(x) != p.it.F(x) || p.js.F(x) != p.it.F(x) {
+				t.Error("Round trip changed F at", x, "Got:", p.bin.F(x), p.js.F(x), "Expected:", p.it.F(x))
+			}
+		}
+	}
+
+	pts := [][]float64{{1, 0}, {0, 1}, {-1, 0.2}, {0, -1}}
+	open, _ := ParametricCurve(pts, Centripetal, false)
+	closed, _ := ParametricCurve(pts, ChordLength, true)
+	for _, c := range []*ParametricSpline{open, closed, closed.ByArcLength()} {
+		bin, _ := c.MarshalBinary()
+		js, _ := c.MarshalJSON()
+		u, v := &ParametricSpline{}, &ParametricSpline{}
+		if err := firstErr(u.UnmarshalBinary(bin), v.UnmarshalJSON(js)); err != nil {
+			t.Error("Parametric decoding failed. Got:", err)
+			continue
+		}
+		for _, s := range []float64{0.1, 1.3, 2.9, 7.2} {
+			want, got, gotJS := c.At(s), u.At(s), v.At(s)
+			if !sameSlice(got, want, 0) || !sameSlice(gotJS, want, 0) {
+				t.Error("Parametric round trip changed At", s, "Got:", got, gotJS, "Expected:", want)
+			}
+		}
+	}
+	bad := &ParametricSpline{}
+	if bad.UnmarshalJSON([]byte(`{"version":1,"type":"parametric","xs":[0,1,2],"points":[[0,1,2],[0,1]],"params":[0,0]}`)) != ErrFormat {
+		t.Error("Expected ErrFormat for a ragged parametric record.")
+	}
+
+	st := StreamingSpline(StreamAkima, 5)
+	for i := 0; i < 8; i++ {
+		st.Push(float64(i), math.Sin(float64(i)))
+	}
+	bin, _ := st.MarshalBinary()
+	js, _ := st.MarshalJSON()
+	u, v := &StreamingInterpolation{}, &StreamingInterpolation{}
+	if err := firstErr(u.UnmarshalBinary(bin), v.UnmarshalJSON(js)); err != nil {
+		t.Fatal("Streaming decoding failed. Got:", err)
+	}
+	for _, s := range []*StreamingInterpolation{st, u, v} {
+		s.Push(8.5, 0.3)
+	}
+	for _, x := range []float64{4.2, 6.6, 8.1} {
+		if u.F(x) != st.F(x) || v.F(x) != st.F(x) {
+			t.Error("Streaming round trip changed F at", x, "Got:", u.F(x), v.F(x), "Expected:", st.F(x))
+		}
+	}
+}
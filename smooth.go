@@ -0,0 +1,199 @@
+package vec
+
+import "math"
+
+/*
+Smoothing filters for sampled series
+
+Each constructor returns a Linop that leaves its input
+untouched and returns a new slice of the same length, or
+ErrParam for a bad parameter. Filters that use a window
+treat the samples as evenly spaced; windows are truncated
+at the ends of the series.
+
+e.g.
+	med, _ := MedianFilter(5)
+	sg, _ := SavGolSmooth(11, 3)
+	smooth := bvd.Smooth(med).Smooth(sg)
+*/
+
+/*
+Moving average over 'window' samples: centred on each
+sample (odd 'window'), or trailing (the sample and the
+window-1 before it)
+
+The window is a count of samples, so this is only a
+moving average over x for evenly spaced x-values.
+Returns ErrParam if 'window' is less than one, or even
+and centred.
+*/
+func MovingAverage(window int, centred bool) (Linop, error) {
+	if window < 1 || (centred && window%2 == 0) {
+		return nil, ErrParam
+	}
+	return func(xs []float64) []float64 {
+		n := len(xs)
+		out := make([]float64, n)
+
+		//prefix sums; sums[i] = xs[0] + ... + xs[i-1]
+		sums := make([]float64, n+1)
+		for i, x := range xs {
+			sums[i+1] = sums[i] + x
+		}
+		for i := range out {
+			lo, hi := i-window+1, i+1
+			if centred {
+				lo, hi = i-window/2, i+window/2+1
+			}
+			if lo < 0 {
+				lo = 0
+			}
+			if hi > n {
+				hi = n
+			}
+			out[i] = (sums[hi] - sums[lo]) / float64(hi-lo)
+		}
+		return out
+	}, nil
+}
+
+/*
+Exponential smoothing: out[0] = xs[0],
+out[i] = alpha*xs[i] + (1-alpha)*out[i-1]
+
+Returns ErrParam unless 0 < alpha <= 1.
+*/
+func ExponentialSmoothing(alpha float64) (Linop, error) {
+	if !(alpha > 0 && alpha <= 1) {
+		return nil, ErrParam
+	}
+	return func(xs []float64) []float64 {
+		out := make([]float64, len(xs))
+		for i, x := range xs {
+			if i == 0 {
+				out[i] = x
+				continue
+			}
+			out[i] = alpha*x + (1-alpha)*out[i-1]
+		}
+		return out
+	}, nil
+}
+
+/*
+Savitzky-Golay smoothing: each sample is replaced by the
+value of the least-squares polynomial of degree 'poly'
+through the 'window' samples around it (the first or last
+window near the ends)
+
+The fit is in the sample index, so the x-values must be
+evenly spaced (see SavGolDeriv, which checks them). Series
+shorter than 'window' are returned unchanged. Returns
+ErrParam if 'window' is even or not greater than 'poly',
+and ErrSingular if the fit cannot be formed.
+*/
+func SavGolSmooth(window int, poly int) (Linop, error) {
+	if window%2 == 0 || window <= poly || poly < 0 {
+		return nil, ErrParam
+	}
+	w, err := savgolWeights(window, poly, 0)
+	if err != nil {
+		return nil, err
+	}
+	return func(xs []float64) []float64 {
+		if len(xs) < window {
+			return append([]float64{}, xs...)
+		}
+		return savgolApply(xs, w)
+	}, nil
+}
+
+/*
+Median filter over a centred window of 'window' samples
+(odd); robust to isolated spikes
+
+As with MovingAverage, the window is a count of samples
+and spans a fixed x-width only for evenly spaced data.
+Returns ErrParam if 'window' is even or less than one.
+*/
+func MedianFilter(window int) (Linop, error) {
+	if window < 1 || window%2 == 0 {
+		return nil, ErrParam
+	}
+	return func(xs []float64) []float64 {
+		n := len(xs)
+		out := make([]float64, n)
+		for i := range out {
+			lo, hi := i-window/2, i+window/2+1
+			if lo < 0 {
+				lo = 0
+			}
+			if hi > n {
+				hi = n
+			}
+			out[i] = median(xs[lo:hi])
+		}
+		return out
+	}, nil
+}
+
+/*
+Whittaker smoothing: minimizes
+
+sum((xs[i] - z[i])^2) + lambda*sum((z[i+1] - 2*z[i] + z[i-1])^2)
+
+by solving the pentadiagonal system (I + lambda*D^T*D) z = xs
+in O(n). Larger 'lambda' gives a smoother result, tending to
+the least-squares line. Returns ErrParam if 'lambda' is
+negative, NaN or infinite.
+
+See:
+P.H.C. Eilers, Anal. Chem. 75(14), 3631-3636 (2003)
+*/
+func WhittakerSmooth(lambda float64) (Linop, error) {
+	if !(lambda >= 0) || math.IsInf(lambda, 1) {
+		return nil, ErrParam
+	}
+	return func(xs []float64) []float64 {
+		n := len(xs)
+		out := append([]float64{}, xs...)
+		if n < 3 || lambda == 0 {
+			return out
+		}
+
+		//diagonals of D^T*D for second differences
+		d0 := make([]float64, n)
+		d1 := make([]float64, n)
+		d2 := make([]float64, n)
+		for i := 0; i < n-2; i++ {
+			d0[i] += lambda
+			d0[i+1] += 4 * lambda
+			d0[i+2] += lambda
+			d1[i] -= 2 * lambda
+			d1[i+1] -= 2 * lambda
+			d2[i] += lambda
+		}
+		for i := range d0 {
+			d0[i] += 1.0
+		}
+		factorPenta(d0, d1, d2).solve(out)
+		return out
+	}, nil
+}
+
+/*
+Applies the filter 'f' to the y-values of 'b' (in x order)
+and returns the result as a new BiVariateData
+
+The filters see only the y-values, so windowed filters
+assume that the x-values of 'b' are evenly spaced.
+Panics with ErrParam if 'f' is nil, e.g. when a filter
+constructor's error was ignored.
+*/
+func (b *BiVariateData) Smooth(f Linop) *BiVariateData {
+	if f == nil {
+		panic(ErrParam)
+	}
+	b.Sort()
+	return &BiVariateData{Xs: append([]float64{}, b.Xs...), Ys: f(b.Ys), isSorted: true}
+}
@@ -0,0 +1,327 @@
+package vec
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+Cubic smoothing splines
+
+Minimizes   sum(w[i]*(y[i] - f(x[i]))^2) + lambda*integral(f''(x)^2)
+
+over all twice-differentiable f. The minimizer is a natural
+cubic spline with knots at the data; its values g at the knots
+follow from the Reinsch algorithm:
+
+	(R + lambda*Q'*W^-1*Q)*gamma = Q'*y,   g = y - lambda*W^-1*Q*gamma
+
+where R is tridiagonal, Q is n x (n-2) tridiagonal, and gamma
+holds the second derivatives at the interior knots.
+
+See:
+P.J. Green & B.W. Silverman, "Nonparametric Regression and
+Generalized Linear Models", ch. 2-3 (1994)
+*/
+
+/*
+Constructs a cubic smoothing spline of 'd' with
+per-point weights 'w' (nil for equal weights)
+and roughness penalty 'lambda'
+
+lambda = 0 interpolates (a natural cubic spline);
+lambda -> Inf tends to the weighted least-squares line.
+The knot x-values must be distinct.
+
+Returns ErrLength if 'w' is not nil and not the length of
+'d', and ErrParam if 'lambda' is negative, NaN or infinite
+or a weight is not finite and positive.
+The result is a natural spline through the smoothed
+values, stored in a new BiVariateData.
+*/
+func SmoothingSpline(d *BiVariateData, w []float64, lambda float64) (*CubicSplineInterpolation, error) {
+	if !(lambda >= 0) || math.IsInf(lambda, 1) {
+		return nil, ErrParam
+	}
+	s, err := newReinsch(d, w)
+	if err != nil {
+		return nil, err
+	}
+	return s.smoothed(lambda), nil
+}
+
+/*
+Constructs a cubic smoothing spline of 'd' with the
+penalty chosen to minimize the generalized cross-validation
+score; returns the spline and the chosen penalty
+
+GCV(lambda) = n*RSS(lambda) / (n - tr(A(lambda)))^2
+
+where A is the hat matrix mapping y onto the smoothed values.
+The trace is computed in O(n) per trial from the band of the
+inverse of the Reinsch matrix. Returns ErrLength and ErrParam
+(for the weights) under the same conditions as SmoothingSpline.
+*/
+func GCVSmoothingSpline(d *BiVariateData, w []float64) (*CubicSplineInterpolation, float64, error) {
+	s, err := newReinsch(d, w)
+	if err != nil {
+		return nil, math.NaN(), err
+	}
+	n := float64(len(s.xs))
+	if len(s.xs) < 3 {
+		return s.smoothed(0.0), 0.0, nil
+	}
+
+	//GCV as a function of log10(lambda/scale)
+	scale := s.scale()
+	gcv := func(p float64) float64 {
+		g, tr, _ := s.fit(scale*math.Pow(10, p), true)
+		rss := 0.0
+		for i := range g {
+			rss += s.w[i] * (s.ys[i] - g[i]) * (s.ys[i] - g[i])
+		}
+		return n * rss / ((n - tr) * (n - tr))
+	}
+
+	//coarse scan, then golden-section search around the best point
+	const lo, hi, steps = -10.0, 10.0, 40
+	best, bestVal := lo, math.Inf(1)
+	for k := 0; k <= steps; k++ {
+		p := lo + (hi-lo)*float64(k)/steps
+		if v := gcv(p); v < bestVal {
+			best, bestVal = p, v
+		}
+	}
+	a, b := best-(hi-lo)/steps, best+(hi-lo)/steps
+	invphi := (math.Sqrt(5) - 1) / 2
+	c, e := b-invphi*(b-a), a+invphi*(b-a)
+	fc, fe := gcv(c), gcv(e)
+	for b-a > 1E-6 {
+		if fc < fe {
+			b, e, fe = e, c, fc
+			c = b - invphi*(b-a)
+			fc = gcv(c)
+		} else {
+			a, c, fc = c, e, fe
+			e = a + invphi*(b-a)
+			fe = gcv(e)
+		}
+	}
+	p := (a + b) / 2
+	if bestVal < gcv(p) {
+		p = best
+	}
+
+	lambda := scale * math.Pow(10, p)
+	return s.smoothed(lambda), lambda, nil
+}
+
+//sorted knots, values and weights for the Reinsch algorithm
+type reinsch struct {
+	xs, ys, w []float64
+	h         []float64
+}
+
+//sorts 'd' (carrying 'w' along) and fills in unit weights
+func newReinsch(d *BiVariateData, w []float64) (*reinsch, error) {
+	N := len(d.Xs)
+	if w != nil && len(w) != N {
+		return nil, ErrLength
+	}
+	if w == nil {
+		w = make([]float64, N)
+		for i := range w {
+			w[i] = 1.0
+		}
+	} else {
+		for _, wi := range w {
+			if !(wi > 0) || math.IsInf(wi, 1) {
+				return nil, ErrParam
+			}
+		}
+		w = append([]float64(nil), w...)
+	}
+	if !sort.Float64sAreSorted(d.Xs) {
+		sort.Sort(weighted{d, w})
+	}
+	d.Sort()
+
+	s := &reinsch{xs: d.Xs, ys: d.Ys, w: w}
+	if N > 1 {
+		s.h, _ = secants(d.Xs, d.Ys)
+	}
+	return s, nil
+}
+
+//sort.Interface over BiVariateData and a parallel weight slice
+type weighted struct {
+	d *BiVariateData
+	w []float64
+}
+
+func (s weighted) Len() int {
+	return s.d.Len()
+}
+
+func (s weighted) Less(i, j int) bool {
+	return s.d.Less(i, j)
+}
+
+func (s weighted) Swap(i, j int) {
+	s.d.Swap(i, j)
+	s.w[i], s.w[j] = s.w[j], s.w[i]
+}
+
+/*
+Natural spline through the smoothed values for penalty
+'lambda'
+
+Its adjoint (see linearSpline) maps weights on the
+smoothed values g = S*y back onto y through
+S' = I - lambda*Q*M^-1*Q'*W^-1.
+*/
+func (s *reinsch) smoothed(lambda float64) *CubicSplineInterpolation {
+	g, _, f := s.fit(lambda, false)
+	d := &BiVariateData{Xs: s.xs, Ys: g, isSorted: true}
+	if len(g) < 2 {
+		return &CubicSplineInterpolation{data: d}
+	}
+	spl := linearSpline(d, SplineEnds{Cond: Natural})
+	if f == nil {
+		return spl
+	}
+
+	natural := spl.adj
+	m := len(g) - 2
+	spl.adj = func(wy []float64, wD []float64) []float64 {
+		u := natural(wy, wD)
+		v := make([]float64, m)
+		for j := 1; j <= m; j++ {
+			for i := j - 1; i <= j+1; i++ {
+				v[j-1] += s.q(i, j) * u[i] / s.w[i]
+			}
+		}
+		f.solve(v)
+		for i := range u {
+			for j := i - 1; j <= i+1; j++ {
+				if j >= 1 && j <= m {
+					u[i] -= lambda * s.q(i, j) * v[j-1]
+				}
+			}
+		}
+		return u
+	}
+	return spl
+}
+
+//ratio of the diagonals of R and Q'*W^-1*Q, which puts lambda on a unit scale
+func (s *reinsch) scale() float64 {
+	tr, tq := 0.0, 0.0
+	for j := 1; j < len(s.xs)-1; j++ {
+		tr += (s.h[j-1] + s.h[j]) / 3.0
+		for i := j - 1; i <= j+1; i++ {
+			q := s.q(i, j)
+			tq += q * q / s.w[i]
+		}
+	}
+	return tr / tq
+}
+
+//element (i, j) of Q, for interior knot 'j'
+func (s *reinsch) q(i int, j int) float64 {
+	switch i {
+	case j - 1:
+		return 1.0 / s.h[j-1]
+	case j:
+		return -1.0/s.h[j-1] - 1.0/s.h[j]
+	case j + 1:
+		return 1.0 / s.h[j]
+	}
+	return 0.0
+}
+
+/*
+Returns the smoothed values for penalty 'lambda', the
+trace of the hat matrix if 'trace' is true, and the
+factorization of M (nil if the values are not smoothed)
+*/
+func (s *reinsch) fit(lambda float64, trace bool) ([]float64, float64, *pentaLDL) {
+	n := len(s.xs)
+	g := append([]float64(nil), s.ys...)
+	if n < 3 || lambda == 0 {
+		return g, float64(n), nil
+	}
+
+	//M = R + lambda*Q'*W^-1*Q on the interior knots 1...n-2
+	m := n - 2
+	d0, d1, d2 := make([]float64, m), make([]float64, m), make([]float64, m)
+	rhs := make([]float64, m)
+	for j := 1; j <= m; j++ {
+		c := j - 1
+		d0[c] = (s.h[j-1] + s.h[j]) / 3.0
+		if j < m {
+			d1[c] = s.h[j] / 6.0
+		}
+		for k := j; k <= j+2 && k <= m; k++ {
+			//rows shared by columns j and k
+			sum := 0.0
+			for i := k - 1; i <= j+1; i++ {
+				sum += s.q(i, j) * s.q(i, k) / s.w[i]
+			}
+			switch k - j {
+			case 0:
+				d0[c] += lambda * sum
+			case 1:
+				d1[c] += lambda * sum
+			case 2:
+				d2[c] = lambda * sum
+			}
+		}
+		rhs[c] = s.q(j-1, j)*s.ys[j-1] + s.q(j, j)*s.ys[j] + s.q(j+1, j)*s.ys[j+1]
+	}
+
+	f := factorPenta(d0, d1, d2)
+	f.solve(rhs)
+	for i := 0; i < n; i++ {
+		qg := 0.0
+		for j := i - 1; j <= i+1; j++ {
+			if j >= 1 && j <= m {
+				qg += s.q(i, j) * rhs[j-1]
+			}
+		}
+		g[i] -= lambda * qg / s.w[i]
+	}
+	if !trace {
+		return g, 0.0, f
+	}
+
+	//tr(A) = n - lambda*sum((Q*M^-1*Q')[i][i]/w[i])
+	s0, s1, s2 := f.inverseBand()
+	band := func(a int, b int) float64 {
+		if a > b {
+			a, b = b, a
+		}
+		switch b - a {
+		case 0:
+			return s0[a-1]
+		case 1:
+			return s1[a-1]
+		case 2:
+			return s2[a-1]
+		}
+		return 0.0
+	}
+	tr := float64(n)
+	for i := 0; i < n; i++ {
+		sum := 0.0
+		for j := i - 1; j <= i+1; j++ {
+			for k := i - 1; k <= i+1; k++ {
+				if j >= 1 && j <= m && k >= 1 && k <= m {
+					sum += s.q(i, j) * s.q(i, k) * band(j, k)
+				}
+			}
+		}
+		tr -= lambda * sum / s.w[i]
+	}
+	return g, tr, f
+}
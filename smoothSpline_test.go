@@ -0,0 +1,119 @@
+package vec
+
+import "testing"
+import "math"
+import "math/rand"
+
+/*
+Test smoothing splines
+
+- lambda = 0 interpolates
+- very large lambda tends to the least-squares line
+- GCV recovers a smooth curve from noisy samples
+- mismatched, non-positive and non-finite weights and bad penalties return errors
+*/
+func TestSmoothingSpline(t *testing.T) {
+	xs := Arange(0, 2*math.Pi, 200)
+	ys := make([]float64, len(xs))
+	rnd := rand.New(rand.NewSource(7))
+	for i, x := range xs {
+		ys[i] = math.Sin(x) + 0.1*rnd.NormFloat64()
+	}
+	bvd := MakeBiVariateData(xs, ys)
+
+	spl, _ := SmoothingSpline(bvd, nil, 0)
+	for i, x := range xs {
+		if math.Abs(spl.F(x)-ys[i]) > 1E-12 {
+			t.Error("SmoothingSpline with lambda = 0 doesn't interpolate at", x)
+			break
+		}
+	}
+
+	line, _ := SmoothingSpline(MakeBiVariateData([]float64{0, 1, 2, 3, 4}, []float64{0, 2, 1, 3, 4}), nil, 1E12)
+	if math.Abs(line.DDF(1.5)) > 1E-6 || math.Abs(line.F(2)-2.0) > 1E-6 || math.Abs(line.DF(2)-0.9) > 1E-6 {
+		t.Error("SmoothingSpline with huge lambda is not the regression line. Got:", line.F(2), line.DF(2))
+	}
+
+	gcv, lambda, _ := GCVSmoothingSpline(bvd, nil)
+	if lambda <= 0 || math.IsNaN(lambda) {
+		t.Error("GCVSmoothingSpline chose a bad penalty:", lambda)
+	}
+	rmsData, rmsFit := 0.0, 0.0
+	for i, x := range xs {
+		rmsData += (ys[i] - math.Sin(x)) * (ys[i] - math.Sin(x))
+		rmsFit += (gcv.F(x) - math.Sin(x)) * (gcv.F(x) - math.Sin(x))
+	}
+	if rmsFit > rmsData/4 {
+		t.Error("GCVSmoothingSpline did not smooth the noise. Fit error:", rmsFit, "Noise:", rmsData)
+	}
+
+	//weights: a point with zero-ish weight is ignored
+	w := make([]float64, 5)
+	for i := range w {
+		w[i] = 1.0
+	}
+	w[2] = 1E-12
+	spl, _ = SmoothingSpline(MakeBiVariateData([]float64{0, 1, 2, 3, 4}, []float64{0, 1, 10, 3, 4}), w, 1E-3)
+	if math.Abs(spl.F(2)-2.0) > 0.1 {
+		t.Error("SmoothingSpline did not honour the weights. Got:", spl.F(2))
+	}
+
+	if _, err := SmoothingSpline(bvd, w, 1.0); err != ErrLength {
+		t.Error("Expected ErrLength for mismatched weights. Got:", err)
+	}
+	if _, err := SmoothingSpline(bvd, nil, -1.0); err != ErrParam {
+		t.Error("Expected ErrParam for a negative penalty. Got:", err)
+	}
+	if _, _, err := GCVSmoothingSpline(bvd, w); err != ErrLength {
+		t.Error("Expected ErrLength for mismatched weights. Got:", err)
+	}
+	d5 := MakeBiVariateData([]float64{0, 1, 2, 3, 4}, []float64{0, 1, 10, 3, 4})
+	for _, bad := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		w[2] = bad
+		if _, err := SmoothingSpline(d5, w, 1.0); err != ErrParam {
+			t.Error("Expected ErrParam for the weight", bad, "Got:", err)
+		}
+		if _, _, err := GCVSmoothingSpline(d5, w); err != ErrParam {
+			t.Error("Expected ErrParam from GCV for the weight", bad, "Got:", err)
+		}
+	}
+}
+
+/*
+Test that a smoothing spline at a moderate penalty solves
+the penalised least-squares problem
+
+- w[i]*(y[i] - g[i]) = lambda*(Q*gamma)[i] at every knot,
+  where gamma holds the second derivatives at the knots
+*/
+func TestSmoothingSplineObjective(t *testing.T) {
+	xs := []float64{0, 0.3, 1, 1.4, 2.2, 3, 3.1, 4, 5.5, 6}
+	ys := []float64{1, 0.2, 2, 1.5, 3, 0.5, 1, 2.5, 0, 1}
+	w := []float64{1, 2, 0.5, 1, 1, 3, 1, 0.7, 1, 2}
+	lambda := 0.8
+	spl, _ := SmoothingSpline(MakeBiVariateData(xs, ys), w, lambda)
+
+	n := len(xs)
+	gamma := make([]float64, n)
+	for j := 1; j < n-1; j++ {
+		gamma[j] = spl.DDF(xs[j])
+	}
+	h := func(i int) float64 { return xs[i+1] - xs[i] }
+	for i := 0; i < n; i++ {
+		//Q[i][j] is nonzero for interior knots j = i-1, i, i+1
+		qg := 0.0
+		if j := i - 1; j >= 1 {
+			qg += gamma[j] / h(j)
+		}
+		if j := i; j >= 1 && j <= n-2 {
+			qg -= gamma[j] * (1/h(j-1) + 1/h(j))
+		}
+		if j := i + 1; j <= n-2 {
+			qg += gamma[j] / h(i)
+		}
+		lhs := w[i] * (ys[i] - spl.F(xs[i]))
+		if math.Abs(lhs-lambda*qg) > 1E-10 {
+			t.Error("SmoothingSpline is not the penalised fit at", xs[i], "Got:", lhs, "Expected:", lambda*qg)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package vec
+
+import "testing"
+import "math"
+
+/*
+Test centred and trailing moving averages
+
+EDGE CASES:
+- windows truncated at the ends
+- bad windows return ErrParam
+*/
+func TestMovingAverage(t *testing.T) {
+	xs := []float64{1, 2, 3, 10, 5}
+	f, _ := MovingAverage(3, true)
+	if out := f(xs); !sameSlice(out, []float64{1.5, 2, 5, 6, 7.5}, 1E-15) {
+		t.Error("Centred moving average is wrong. Got:", out, "Expected:", []float64{1.5, 2, 5, 6, 7.5})
+	}
+	f, _ = MovingAverage(2, false)
+	if out := f(xs); !sameSlice(out, []float64{1, 1.5, 2.5, 6.5, 7.5}, 1E-15) {
+		t.Error("Trailing moving average is wrong. Got:", out, "Expected:", []float64{1, 1.5, 2.5, 6.5, 7.5})
+	}
+	if _, err := MovingAverage(2, true); err != ErrParam {
+		t.Error("Expected ErrParam for an even centred window. Got:", err)
+	}
+	if _, err := MovingAverage(0, false); err != ErrParam {
+		t.Error("Expected ErrParam for an empty window. Got:", err)
+	}
+}
+
+/*
+Test exponential smoothing
+
+EDGE CASES:
+- alpha outside (0, 1] returns ErrParam
+*/
+func TestExponentialSmoothing(t *testing.T) {
+	f, _ := ExponentialSmoothing(0.5)
+	if out := f([]float64{2, 4, 0}); !sameSlice(out, []float64{2, 3, 1.5}, 1E-15) {
+		t.Error("Exponential smoothing is wrong. Got:", out, "Expected:", []float64{2, 3, 1.5})
+	}
+	if _, err := ExponentialSmoothing(0); err != ErrParam {
+		t.Error("Expected ErrParam for alpha = 0. Got:", err)
+	}
+	if _, err := ExponentialSmoothing(1.5); err != ErrParam {
+		t.Error("Expected ErrParam for alpha > 1. Got:", err)
+	}
+}
+
+/*
+Test Savitzky-Golay smoothing
+
+- polynomials up to the fit degree pass through unchanged
+
+EDGE CASES:
+- series shorter than the window are returned unchanged
+- wide windows with high fit degrees
+- bad windows return ErrParam
+*/
+func TestSavGolSmooth(t *testing.T) {
+	xs := make([]float64, 40)
+	for i := range xs {
+		x := float64(i) / 10
+		xs[i] = x*x*x - 2*x + 1
+	}
+	f, _ := SavGolSmooth(9, 3)
+	if out := f(xs); !sameSlice(out, xs, 1E-10) {
+		t.Error("SavGolSmooth changed a cubic. Got:", out)
+	}
+	if out := f(xs[:5]); !sameSlice(out, xs[:5], 0) {
+		t.Error("SavGolSmooth changed a short series. Got:", out, "Expected:", xs[:5])
+	}
+	ones := make([]float64, 400)
+	for i := range ones {
+		ones[i] = 1
+	}
+	f, err := SavGolSmooth(201, 12)
+	if err != nil {
+		t.Fatal("SavGolSmooth failed for a wide window. Got:", err)
+	}
+	if out := f(ones); !sameSlice(out, ones, 1E-9) {
+		t.Error("SavGolSmooth changed a constant with a wide window. Got:", out[:3], out[len(out)-3:])
+	}
+	if _, err := SavGolSmooth(8, 3); err != ErrParam {
+		t.Error("Expected ErrParam for an even window. Got:", err)
+	}
+	if _, err := SavGolSmooth(3, 3); err != ErrParam {
+		t.Error("Expected ErrParam for a window no longer than the degree. Got:", err)
+	}
+}
+
+/*
+Test the median filter
+
+- an isolated spike is removed
+*/
+func TestMedianFilter(t *testing.T) {
+	f, _ := MedianFilter(3)
+	if out := f([]float64{1, 1, 50, 1, 2, 3}); !sameSlice(out, []float64{1, 1, 1, 2, 2, 2.5}, 0) {
+		t.Error("Median filter is wrong. Got:", out, "Expected:", []float64{1, 1, 1, 2, 2, 2.5})
+	}
+	if _, err := MedianFilter(4); err != ErrParam {
+		t.Error("Expected ErrParam for an even window. Got:", err)
+	}
+}
+
+/*
+Test Whittaker smoothing
+
+- lines are unchanged and noise is reduced
+- matches a dense solve of (I + lambda*D^T*D) z = y
+
+EDGE CASES:
+- negative or infinite lambda returns ErrParam
+*/
+func TestWhittakerSmooth(t *testing.T) {
+	line := Arange(0, 5, 50)
+	f, _ := WhittakerSmooth(100)
+	if out := f(line); !sameSlice(out, line, 1E-10) {
+		t.Error("Whittaker smoothing changed a line. Got:", out)
+	}
+	noisy := make([]float64, 200)
+	clean := make([]float64, 200)
+	for i := range noisy {
+		clean[i] = math.Sin(float64(i) / 20)
+		noisy[i] = clean[i] + 0.2*math.Sin(float64(i*i)*1.7)
+	}
+	f, _ = WhittakerSmooth(50)
+	out := f(noisy)
+	var before, after float64
+	for i := range out {
+		before += (noisy[i] - clean[i]) * (noisy[i] - clean[i])
+		after += (out[i] - clean[i]) * (out[i] - clean[i])
+	}
+	if after > before/5 {
+		t.Error("Whittaker smoothing did not reduce the noise. Got:", after, "Expected below:", before/5)
+	}
+
+	y := []float64{1, 3, 2, 5, 4, 6}
+	n, lambda := len(y), 2.0
+	A := zeros(n, n)
+	for i := 0; i < n; i++ {
+		A[i][i] = 1
+	}
+	for r := 0; r < n-2; r++ {
+		d := []float64{1, -2, 1}
+		for a := 0; a < 3; a++ {
+			for b := 0; b < 3; b++ {
+				A[r+a][r+b] += lambda * d[a] * d[b]
+			}
+		}
+	}
+	want, _ := solveDense(A, append([]float64{}, y...))
+	f, _ = WhittakerSmooth(lambda)
+	if got := f(y); !sameSlice(got, want, 1E-12) {
+		t.Error("Whittaker smoothing is wrong. Got:", got, "Expected:", want)
+	}
+
+	if _, err := WhittakerSmooth(-1); err != ErrParam {
+		t.Error("Expected ErrParam for a negative lambda. Got:", err)
+	}
+	if _, err := WhittakerSmooth(math.Inf(1)); err != ErrParam {
+		t.Error("Expected ErrParam for an infinite lambda. Got:", err)
+	}
+}
+
+/*
+Test BiVariateData.Smooth
+
+- the filter runs on the y-values in x order
+
+EDGE CASES:
+- a nil filter panics with ErrParam
+*/
+func TestDataSmooth(t *testing.T) {
+	d := MakeBiVariateData([]float64{2, 0, 1}, []float64{3, 1, 2})
+	f, _ := MovingAverage(3, true)
+	s := d.Smooth(f)
+	if !sameSlice(s.Xs, []float64{0, 1, 2}, 0) || !sameSlice(s.Ys, []float64{1.5, 2, 2.5}, 1E-15) {
+		t.Error("BiVariateData.Smooth is wrong. Got:", s.Xs, s.Ys, "Expected:", []float64{0, 1, 2}, []float64{1.5, 2, 2.5})
+	}
+	defer func() {
+		if r := recover(); r != ErrParam {
+			t.Error("Expected a nil filter to panic with ErrParam. Got:", r)
+		}
+	}()
+	d.Smooth(nil)
+}
@@ -0,0 +1,124 @@
+package vec
+
+import "math"
+
+/*
+Antiderivative of a cubic spline
+
+A piecewise quartic, zero at the first knot, that stores
+the integral up to each knot so that evaluating the running
+integral costs one segment rather than a sum over all of them.
+*/
+
+//SplineAntiderivative type def
+type SplineAntiderivative struct {
+	spline *CubicSplineInterpolation
+	cum    []float64 //integral from Xs[0] to Xs[i]
+	ext    Extrapolation
+}
+
+/*
+Returns the antiderivative of 's' that is zero at the
+first knot, i.e. F(x) = s.Integral(Xs[0], x)
+
+The antiderivative inherits the extrapolation policy of 's'.
+Returns ErrLength if 's' has fewer than two points.
+*/
+func (s *CubicSplineInterpolation) Antiderivative() (*SplineAntiderivative, error) {
+	N := len(s.data.Xs)
+	if N < 2 {
+		return nil, ErrLength
+	}
+	s.data.Sort()
+	cum := make([]float64, N)
+	for i := 1; i < N; i++ {
+		cum[i] = cum[i-1] + s.segIntegral(i-1, 0.0, 1.0)
+	}
+	return &SplineAntiderivative{spline: s, cum: cum, ext: s.ext}, nil
+}
+
+/*
+Returns a copy of 'q' that uses the policy 'ext' outside
+the data endpoints; 'q' itself is unchanged
+*/
+func (q *SplineAntiderivative) Extrapolate(ext Extrapolation) *SplineAntiderivative {
+	out := *q
+	out.ext = ext
+	return &out
+}
+
+//Returns the first and last x-values of the data
+func (q *SplineAntiderivative) Domain() (float64, float64) {
+	return q.spline.Domain()
+}
+
+//Returns the integral of the spline from the first knot to 'x'
+func (q *SplineAntiderivative) F(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(q, q.ext, x, 0); ok {
+		return out
+	}
+	xs := q.spline.data.Xs
+	i := bracket(xs, x)
+	return q.cum[i] + q.spline.segIntegral(i, 0.0, (x-xs[i])/(xs[i+1]-xs[i]))
+}
+
+//First derivative (the spline itself) at 'x'
+func (q *SplineAntiderivative) DF(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(q, q.ext, x, 1); ok {
+		return out
+	}
+	xs := q.spline.data.Xs
+	i := bracket(xs, x)
+	a, b, c, d, h := q.spline.segment(i)
+	t := (x - xs[i]) / h
+	return a + b*t + c*t*t + d*t*t*t
+}
+
+//Second derivative (the spline's first derivative) at 'x'
+func (q *SplineAntiderivative) DDF(x float64) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	if out, ok := extrapolate(q, q.ext, x, 2); ok {
+		return out
+	}
+	xs := q.spline.data.Xs
+	i := bracket(xs, x)
+	_, b, c, d, h := q.spline.segment(i)
+	t := (x - xs[i]) / h
+	return (b + 2*c*t + 3*d*t*t) / h
+}
+
+/*
+Returns the definite integral of the antiderivative
+from 'a' to 'b' (each segment is integrated exactly)
+*/
+func (q *SplineAntiderivative) Integral(a float64, b float64) float64 {
+	if notRat(a) || notRat(b) {
+		return math.NaN()
+	}
+	if a > b {
+		return -q.Integral(b, a)
+	}
+	if out, ok := extrapIntegral(q, q.ext, a, b); ok {
+		return out
+	}
+
+	xs := q.spline.data.Xs
+	return segSum(q.spline.data, a, b, func(i int, x1 float64, x2 float64) float64 {
+		a, b, c, d, h := q.spline.segment(i)
+
+		//double primitive of the segment cubic in t
+		prim := func(x float64) float64 {
+			t := (x - xs[i]) / h
+			return q.cum[i]*t + h*(a*t*t/2.0+b*t*t*t/6.0+c*t*t*t*t/12.0+d*t*t*t*t*t/20.0)
+		}
+		return h * (prim(x2) - prim(x1))
+	})
+}
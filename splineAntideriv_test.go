@@ -0,0 +1,59 @@
+package vec
+
+import "testing"
+import "math"
+
+/*
+Test the antiderivative of a cubic spline
+
+- F, DF and DDF match the spline's integral, F and DF
+- its own integral matches quadrature, outside the data too
+
+EDGE CASES:
+- a single point returns ErrLength
+*/
+func TestSplineAntiderivative(t *testing.T) {
+	xs := []float64{0, 0.4, 1, 1.7, 2.5, 3}
+	ys := []float64{1, 2, 0, -1, 0.5, 2}
+	s := CubicSpline(MakeBiVariateData(xs, ys))
+	q, _ := s.Antiderivative()
+
+	for _, x := range Arange(-0.5, 3.5, 17) {
+		if math.Abs(q.F(x)-s.Integral(0, x)) > 1E-12 {
+			t.Error("Antiderivative is wrong at", x, "Got:", q.F(x), "Expected:", s.Integral(0, x))
+		}
+		if math.Abs(q.DF(x)-s.F(x)) > 1E-12 {
+			t.Error("Antiderivative DF is wrong at", x, "Got:", q.DF(x), "Expected:", s.F(x))
+		}
+		if math.Abs(q.DDF(x)-s.DF(x)) > 1E-12 {
+			t.Error("Antiderivative DDF is wrong at", x, "Got:", q.DDF(x), "Expected:", s.DF(x))
+		}
+	}
+
+	for _, ab := range [][2]float64{{0, 3}, {0.5, 0.6}, {2.9, 0.1}, {-1, 4}} {
+		want, _ := Integral(q.F, ab[0], ab[1])
+		if math.Abs(q.Integral(ab[0], ab[1])-want) > 1E-8 {
+			t.Error("Integral of antiderivative is wrong on", ab, "Got:", q.Integral(ab[0], ab[1]), "Expected:", want)
+		}
+	}
+
+	if _, err := CubicSpline(MakeBiVariateData([]float64{1}, []float64{1})).Antiderivative(); err != ErrLength {
+		t.Error("Expected ErrLength for a single point. Got:", err)
+	}
+}
+
+/*
+Test CumulativeIntegral
+
+- exact for a line, from unsorted data
+*/
+func TestCumulativeIntegral(t *testing.T) {
+	xs := []float64{3, 0, 1, 2}
+	ys := []float64{7, 1, 3, 5} //y = 2x + 1
+	c := CumulativeIntegral(MakeBiVariateData(xs, ys))
+	for i, x := range c.Xs {
+		if math.Abs(c.Ys[i]-(x*x+x)) > 1E-12 {
+			t.Error("Cumulative integral is wrong at", x, "Got:", c.Ys[i], "Expected:", x*x+x)
+		}
+	}
+}
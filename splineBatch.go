@@ -0,0 +1,139 @@
+package vec
+
+import (
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+/*
+Batch evaluation of cubic splines
+
+Sorted query slices are evaluated with a single merge pass
+over the knots; unsorted ones use a binary search per point.
+Batches larger than batchMin are split into NumCPU()
+contiguous pieces, as in PPmap.
+*/
+
+//smallest batch worth splitting across goroutines
+const batchMin = 4096
+
+/*
+Evaluates the spline at each of 'xs' into 'out'
+
+Panics with ErrLength if len(out) != len(xs).
+*/
+func (s *CubicSplineInterpolation) FBatch(xs []float64, out []float64) {
+	s.batch(xs, out, 0)
+}
+
+/*
+Evaluates the first derivative at each of 'xs' into 'out'
+
+Panics with ErrLength if len(out) != len(xs).
+*/
+func (s *CubicSplineInterpolation) DFBatch(xs []float64, out []float64) {
+	s.batch(xs, out, 1)
+}
+
+/*
+Evaluates the second derivative at each of 'xs' into 'out'
+
+Panics with ErrLength if len(out) != len(xs).
+*/
+func (s *CubicSplineInterpolation) DDFBatch(xs []float64, out []float64) {
+	s.batch(xs, out, 2)
+}
+
+func (s *CubicSplineInterpolation) batch(xs []float64, out []float64, deriv int) {
+	if len(out) != len(xs) {
+		panic(ErrLength)
+	}
+
+	//Edge case - fewer than two points has no segments
+	if len(s.data.Xs) < 2 {
+		f := [3]func(float64) float64{s.F, s.DF, s.DDF}[deriv]
+		for i, x := range xs {
+			out[i] = f(x)
+		}
+		return
+	}
+
+	//sort up front; the workers only read the data
+	s.data.Sort()
+
+	NTHREADS := runtime.NumCPU()
+	l := len(xs)
+	if NTHREADS <= 1 || l < batchMin {
+		s.evalRange(xs, out, deriv)
+		return
+	}
+
+	batch_size := l / NTHREADS
+	wg := new(sync.WaitGroup)
+	wg.Add(NTHREADS)
+	panics := make([]interface{}, NTHREADS)
+	for i := 0; i < NTHREADS; i++ {
+		start, end := i*batch_size, (i+1)*batch_size
+		if i == NTHREADS-1 {
+			end = l
+		}
+		go func(i int, s0 int, e0 int) {
+			defer wg.Done()
+			//re-raised below (e.g. ExtrapolateError) so the caller can recover
+			defer func() { panics[i] = recover() }()
+			s.evalRange(xs[s0:e0], out[s0:e0], deriv)
+		}(i, start, end)
+	}
+	wg.Wait()
+	for _, p := range panics {
+		if p != nil {
+			panic(p)
+		}
+	}
+}
+
+//evaluates one contiguous piece of a batch
+func (s *CubicSplineInterpolation) evalRange(xs []float64, out []float64, deriv int) {
+	kx := s.data.Xs
+	merge := sort.Float64sAreSorted(xs)
+
+	//coefficients of the current segment
+	seg := -1
+	var a, b, c, d, h float64
+
+	i := 0
+	for j, x := range xs {
+		if notRat(x) {
+			out[j] = math.NaN()
+			continue
+		}
+		if v, ok := extrapolate(s, s.ext, x, deriv); ok {
+			out[j] = v
+			continue
+		}
+
+		if merge {
+			for i < len(kx)-2 && kx[i+1] <= x {
+				i++
+			}
+		} else {
+			i = bracket(kx, x)
+		}
+		if i != seg {
+			a, b, c, d, h = s.segment(i)
+			seg = i
+		}
+
+		t := (x - kx[i]) / h
+		switch deriv {
+		case 0:
+			out[j] = a + b*t + c*t*t + d*t*t*t
+		case 1:
+			out[j] = (b + 2*c*t + 3*d*t*t) / h
+		default:
+			out[j] = (2*c + 6*d*t) / (h * h)
+		}
+	}
+}
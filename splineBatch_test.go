@@ -0,0 +1,62 @@
+package vec
+
+import "testing"
+import "math"
+import "sort"
+
+/*
+Test batch evaluation of splines
+
+- F, DF and DDF batches match point-by-point evaluation
+for unsorted, sorted and short queries, with each
+extrapolation policy
+*/
+func TestSplineBatch(t *testing.T) {
+	xs := Arange(0, 10, 40)
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = math.Sin(x)
+	}
+
+	//unsorted and sorted queries, large enough to be split
+	qs := make([]float64, 3*batchMin)
+	for i := range qs {
+		qs[i] = math.Mod(float64(i)*0.6180339887, 12) - 1
+	}
+	sorted := append([]float64{}, qs...)
+	sort.Float64s(sorted)
+
+	for _, ext := range []Extrapolation{ExtrapolatePoly, ExtrapolateLinear, ExtrapolateNaN} {
+		s := CubicSpline(MakeBiVariateData(xs, ys)).Extrapolate(ext)
+		fs := []func(float64) float64{s.F, s.DF, s.DDF}
+		batches := []func([]float64, []float64){s.FBatch, s.DFBatch, s.DDFBatch}
+		for k := range fs {
+			for _, q := range [][]float64{qs, sorted, qs[:10]} {
+				out := make([]float64, len(q))
+				batches[k](q, out)
+				for i, x := range q {
+					want := fs[k](x)
+					if out[i] != want && !(math.IsNaN(want) && math.IsNaN(out[i])) {
+						t.Error("Batch derivative", k, "is wrong at", x, "Got:", out[i], "Expected:", want)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+/*
+Test that ExtrapolateError panics from the worker goroutines
+reach the caller
+*/
+func TestSplineBatchPanics(t *testing.T) {
+	s := CubicSpline(MakeBiVariateData(Arange(0, 1, 5), Arange(0, 1, 5))).Extrapolate(ExtrapolateError)
+	qs := Arange(0, 2, 2*batchMin)
+	defer func() {
+		if r := recover(); r != ErrDomain {
+			t.Error("Expected the batch to panic with ErrDomain. Got:", r)
+		}
+	}()
+	s.FBatch(qs, make([]float64, len(qs)))
+}
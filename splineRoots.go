@@ -0,0 +1,233 @@
+package vec
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+Closed-form roots, extrema, and inflection points of
+a CubicSplineInterpolation
+
+Every segment is a known cubic in t = (x - Xs[i])/h, so its
+roots are found directly (Cardano / trigonometric method,
+polished by Newton's method) rather than by bracketing with
+FindRoot. Only points within the data range are returned,
+in ascending order. Segments on which the function is
+identically zero are skipped.
+*/
+
+/*
+Returns every x in the data range where F(x) == c
+
+(A double root -- the curve touching 'c' -- is found
+only if rounding doesn't push it off the axis.)
+*/
+func (s *CubicSplineInterpolation) Roots(c float64) []float64 {
+	return s.segmentRoots(func(a, b, cc, d float64) []float64 {
+		return unitRoots(a-c, b, cc, d)
+	})
+}
+
+/*
+Returns every local extremum of F in the data range
+(the roots of DF where DF changes sign); use DDF or the
+neighbouring values to tell maxima from minima
+
+A zero of DF on a knot is only reported if DF has opposite,
+non-zero signs on either side of it, so the ends of a flat
+segment (e.g. MonotoneSpline on step data) and the end
+knots are never reported.
+*/
+func (s *CubicSplineInterpolation) Extrema() []float64 {
+	roots := s.segmentRoots(func(a, b, c, d float64) []float64 {
+		//a double root of DF is a stationary inflection, not an extremum
+		disc := 4*c*c - 12*b*d
+		if d != 0 && math.Abs(disc) <= 1E-14*(4*c*c+math.Abs(12*b*d)) {
+			return nil
+		}
+		return unitRoots(b, 2*c, 3*d, 0)
+	})
+
+	//simple roots inside a segment always change sign; check the knots
+	xs := s.data.Xs
+	out := roots[:0]
+	for _, x := range roots {
+		i := bracket(xs, x)
+		tol := 1E-9 * (xs[i+1] - xs[i])
+		k := -1
+		if x-xs[i] <= tol {
+			k = i
+		} else if xs[i+1]-x <= tol {
+			k = i + 1
+		}
+		if k < 0 {
+			out = append(out, x)
+			continue
+		}
+		if k == 0 || k == len(xs)-1 {
+			continue
+		}
+		left, right := s.dfSign(k, false), s.dfSign(k, true)
+		if left != 0 && right != 0 && opp(left, right) {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+/*
+Sign (-1, 0 or 1) of DF just to the right (or left) of
+knot 'k', from the first non-zero term of the Taylor
+expansion of the neighbouring segment; 0 if it is flat
+*/
+func (s *CubicSplineInterpolation) dfSign(k int, right bool) float64 {
+	var terms [3]float64
+	if right {
+		_, b, c, d, _ := s.segment(k)
+		terms = [3]float64{b, 2 * c, 3 * d}
+	} else {
+		//expand about t = 1; odd powers of (t-1) are negative
+		_, b, c, d, _ := s.segment(k - 1)
+		terms = [3]float64{b + 2*c + 3*d, -(2*c + 6*d), 3 * d}
+	}
+	for _, v := range terms {
+		if v > 0 {
+			return 1
+		} else if v < 0 {
+			return -1
+		}
+	}
+	return 0
+}
+
+/*
+Returns the inflection points of F in the data range:
+the roots of DDF inside segments, and any knot where
+DDF changes sign (possible for splines that are only
+once differentiable, e.g. MonotoneSpline)
+*/
+func (s *CubicSplineInterpolation) Inflections() []float64 {
+	out := s.segmentRoots(func(a, b, c, d float64) []float64 {
+		return unitRoots(2*c, 6*d, 0, 0)
+	})
+	for i := 1; i < len(s.data.Xs)-1; i++ {
+		_, _, cl, dl, hl := s.segment(i - 1)
+		_, _, cr, _, hr := s.segment(i)
+		left := (2*cl + 6*dl) / (hl * hl)
+		right := 2 * cr / (hr * hr)
+		if left != 0 && right != 0 && opp(left, right) {
+			out = append(out, s.data.Xs[i])
+		}
+	}
+	sort.Float64s(out)
+	return out
+}
+
+/*
+Applies 'roots' to the coefficients of each segment,
+maps the t-roots back to x, and removes the duplicates
+found on both sides of a knot
+*/
+func (s *CubicSplineInterpolation) segmentRoots(roots func(a, b, c, d float64) []float64) []float64 {
+	var out []float64
+	if len(s.coeffs) < 2 {
+		return out
+	}
+	s.data.Sort()
+	for i := 0; i < len(s.data.Xs)-1; i++ {
+		a, b, c, d, h := s.segment(i)
+		for _, t := range roots(a, b, c, d) {
+			x := s.data.Xs[i] + t*h
+			if n := len(out); n > 0 && x-out[n-1] <= 1E-9*h {
+				continue
+			}
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+/*
+Real roots of c0 + c1*t + c2*t^2 + c3*t^3 in [0, 1],
+in ascending order (none if the polynomial is identically zero)
+*/
+func unitRoots(c0 float64, c1 float64, c2 float64, c3 float64) []float64 {
+	m := math.Max(math.Max(math.Abs(c0), math.Abs(c1)), math.Max(math.Abs(c2), math.Abs(c3)))
+	if m == 0 {
+		return nil
+	}
+	small := 1E-12 * m
+
+	var ts []float64
+	switch {
+	case math.Abs(c3) > small:
+		ts = cubicRoots(c2/c3, c1/c3, c0/c3)
+	case math.Abs(c2) > small:
+		ts = quadRoots(c0, c1, c2)
+	case math.Abs(c1) > small:
+		ts = []float64{-c0 / c1}
+	}
+
+	p := func(t float64) float64 {
+		return c0 + t*(c1+t*(c2+t*c3))
+	}
+	dp := func(t float64) float64 {
+		return c1 + t*(2*c2+t*3*c3)
+	}
+
+	var out []float64
+	for _, t := range ts {
+		//polish
+		for k := 0; k < 3; k++ {
+			if der := dp(t); der != 0 {
+				t -= p(t) / der
+			}
+		}
+		if t >= -1E-9 && t <= 1+1E-9 {
+			out = append(out, math.Max(0, math.Min(1, t)))
+		}
+	}
+	sort.Float64s(out)
+	return out
+}
+
+//real roots of c0 + c1*t + c2*t^2 (c2 != 0)
+func quadRoots(c0 float64, c1 float64, c2 float64) []float64 {
+	disc := c1*c1 - 4*c2*c0
+	if disc < 0 {
+		return nil
+	}
+	//avoid cancellation
+	q := -(c1 + math.Copysign(math.Sqrt(disc), c1)) / 2
+	if q == 0 {
+		return []float64{0.0}
+	}
+	return []float64{q / c2, c0 / q}
+}
+
+/*
+Real roots of t^3 + A*t^2 + B*t + C
+
+See:
+Numerical Recipes, 3rd ed., section 5.6
+*/
+func cubicRoots(A float64, B float64, C float64) []float64 {
+	Q := (A*A - 3*B) / 9
+	R := (2*A*A*A - 9*A*B + 27*C) / 54
+	if R*R < Q*Q*Q {
+		theta := math.Acos(R / math.Sqrt(Q*Q*Q))
+		sq := -2 * math.Sqrt(Q)
+		return []float64{
+			sq*math.Cos(theta/3) - A/3,
+			sq*math.Cos((theta+2*math.Pi)/3) - A/3,
+			sq*math.Cos((theta-2*math.Pi)/3) - A/3,
+		}
+	}
+	a := -math.Copysign(math.Cbrt(math.Abs(R)+math.Sqrt(R*R-Q*Q*Q)), R)
+	b := 0.0
+	if a != 0 {
+		b = Q / a
+	}
+	return []float64{a + b - A/3}
+}
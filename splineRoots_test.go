@@ -0,0 +1,55 @@
+package vec
+
+import "testing"
+import "math"
+
+/*
+Test roots, extrema and inflection points of
+a spline through sin() on (0.1, 6.2)
+*/
+func TestSplineRoots(t *testing.T) {
+	xs := Arange(0.1, 6.2, 100)
+	xs = append(xs, 6.2)
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = math.Sin(x)
+	}
+	spl, _ := CubicSplineEnds(MakeBiVariateData(xs, ys), SplineEnds{Cond: NotAKnot})
+
+	if r := spl.Roots(0); !sameSlice(r, []float64{math.Pi}, 1E-6) {
+		t.Error("Roots(0) is wrong. Got:", r)
+	}
+	if r := spl.Roots(0.5); !sameSlice(r, []float64{math.Pi / 6, 5 * math.Pi / 6}, 1E-6) {
+		t.Error("Roots(0.5) is wrong. Got:", r)
+	}
+	for _, x := range spl.Roots(-0.25) {
+		if math.Abs(spl.F(x)+0.25) > 1E-14 {
+			t.Error("Roots(-0.25) returned a point that isn't a root:", x)
+		}
+	}
+	if r := spl.Roots(2.0); len(r) != 0 {
+		t.Error("Roots(2) found roots that don't exist:", r)
+	}
+	if r := spl.Extrema(); !sameSlice(r, []float64{math.Pi / 2, 3 * math.Pi / 2}, 1E-5) {
+		t.Error("Extrema() is wrong. Got:", r)
+	}
+	if r := spl.Inflections(); !sameSlice(r, []float64{math.Pi}, 1E-4) {
+		t.Error("Inflections() is wrong. Got:", r)
+	}
+
+	//flat segments are not extrema; a turn on a knot is
+	steps := MonotoneSpline(MakeBiVariateData([]float64{0, 1, 2, 3, 4, 5}, []float64{0, 0, 1, 1, 2, 2}))
+	if r := steps.Extrema(); len(r) != 0 {
+		t.Error("Extrema() reported the ends of flat segments:", r)
+	}
+	peak := CubicSpline(MakeBiVariateData([]float64{-1, 0, 1}, []float64{0, 1, 0}))
+	if r := peak.Extrema(); !sameSlice(r, []float64{0}, 1E-15) {
+		t.Error("Extrema() on a knot is wrong. Got:", r, "Expected:", 0)
+	}
+
+	//a root exactly on a knot is reported once
+	lin := CubicSpline(MakeBiVariateData([]float64{0, 1, 2}, []float64{-1, 0, 1}))
+	if r := lin.Roots(0); !sameSlice(r, []float64{1}, 1E-15) {
+		t.Error("Roots() on a knot is wrong. Got:", r)
+	}
+}
@@ -0,0 +1,164 @@
+package vec
+
+/*
+Streaming construction of local splines
+
+Points are pushed in increasing x order. Because the knot
+slopes of the local splines depend only on a few neighbouring
+points, each push recomputes only the slopes near the end
+(and, with a sliding window, near the start) instead of
+rebuilding the whole spline.
+*/
+
+//StreamMethod - local spline built by a StreamingInterpolation
+type StreamMethod int
+
+const (
+	//StreamMonotone - PCHIP, as MonotoneSpline
+	StreamMonotone StreamMethod = iota
+	//StreamAkima - as AkimaSpline
+	StreamAkima
+	//StreamModifiedAkima - as ModifiedAkimaSpline
+	StreamModifiedAkima
+	//StreamSteffen - as SteffenSpline
+	StreamSteffen
+)
+
+/*
+StreamingInterpolation type def
+
+Embeds the CubicSplineInterpolation of the current points,
+so it evaluates, integrates, etc. like any other spline
+once it holds at least two points.
+*/
+type StreamingInterpolation struct {
+	*CubicSplineInterpolation
+	method StreamMethod
+	slopes func([]float64, []float64) []float64
+	reach  int //knots on either side that each slope depends on
+	window int
+	m      []float64
+}
+
+/*
+Constructs an empty streaming spline of type 'method'
+
+If 'window' is greater than zero, only the last 'window'
+points (at least two) are kept; older points are evicted
+as new ones are pushed.
+*/
+func StreamingSpline(method StreamMethod, window int) *StreamingInterpolation {
+	s := &StreamingInterpolation{
+		CubicSplineInterpolation: &CubicSplineInterpolation{data: &BiVariateData{isSorted: true}},
+		method:                   method,
+		slopes:                   pchipSlopes,
+		reach:                    1,
+		window:                   window,
+	}
+	if window == 1 {
+		s.window = 2
+	}
+	switch method {
+	case StreamAkima, StreamModifiedAkima:
+		modified := method == StreamModifiedAkima
+		s.slopes = func(xs []float64, ys []float64) []float64 {
+			return akimaSlopes(xs, ys, modified)
+		}
+		s.reach = 2
+	case StreamSteffen:
+		s.slopes = steffenSlopes
+	}
+	return s
+}
+
+/*
+Adds the point (x, y) to the end of the spline
+
+Returns ErrNotFinite if 'x' or 'y' is NaN or +/-Inf, and
+ErrUnordered if 'x' is not greater than the last x-value.
+*/
+func (s *StreamingInterpolation) Push(x float64, y float64) error {
+	d := s.data
+	if notRat(x) || notRat(y) {
+		return ErrNotFinite
+	}
+	if len(d.Xs) > 0 && x <= d.Xs[len(d.Xs)-1] {
+		return ErrUnordered
+	}
+	d.Append(x, y)
+	s.m = append(s.m, 0.0)
+	s.coeffs = append(s.coeffs, 0.0)
+
+	if s.window > 0 && len(d.Xs) > s.window {
+		d.Xs, d.Ys = d.Xs[1:], d.Ys[1:]
+		s.m, s.coeffs = s.m[1:], s.coeffs[1:]
+		s.refit(0, s.reach+1)
+	}
+	//the end formulas reach further in, so refit short splines fully
+	N := len(d.Xs)
+	if N <= 2*s.reach+2 {
+		s.refit(0, N)
+	} else {
+		s.refit(N-1-s.reach, N)
+	}
+	return nil
+}
+
+/*
+Returns a copy of 's' that uses the policy 'ext' outside
+the data endpoints; 's' itself is unchanged and the two
+can be pushed to independently
+*/
+func (s *StreamingInterpolation) Extrapolate(ext Extrapolation) *StreamingInterpolation {
+	d := s.data
+	out := *s
+	out.CubicSplineInterpolation = &CubicSplineInterpolation{
+		data:   &BiVariateData{Xs: append([]float64{}, d.Xs...), Ys: append([]float64{}, d.Ys...), isSorted: true},
+		coeffs: append([]float64{}, s.coeffs...),
+		ext:    ext,
+	}
+	out.m = append([]float64{}, s.m...)
+	return &out
+}
+
+//Returns the number of points currently held
+func (s *StreamingInterpolation) Len() int {
+	return len(s.data.Xs)
+}
+
+/*
+Recomputes the slopes of knots 'from' to 'to' (exclusive)
+from the points within twice the reach on either side, which
+keeps them clear of the end formulas of the sub-slice
+*/
+func (s *StreamingInterpolation) refit(from int, to int) {
+	xs, ys := s.data.Xs, s.data.Ys
+	N := len(xs)
+	if N < 2 {
+		return
+	}
+	if from < 0 {
+		from = 0
+	}
+	if to > N {
+		to = N
+	}
+	a, b := from-2*s.reach, to+2*s.reach
+	if a < 0 {
+		a = 0
+	}
+	if b > N {
+		b = N
+	}
+	m := s.slopes(xs[a:b], ys[a:b])
+	copy(s.m[from:to], m[from-a:to-a])
+
+	//coefficients are the slopes scaled by the width of the next segment
+	for i := from; i < to; i++ {
+		if i < N-1 {
+			s.coeffs[i] = s.m[i] * (xs[i+1] - xs[i])
+		} else {
+			s.coeffs[i] = s.m[i] * (xs[i] - xs[i-1])
+		}
+	}
+}
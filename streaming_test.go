@@ -0,0 +1,83 @@
+package vec
+
+import "testing"
+import "math"
+
+/*
+Test StreamingSpline against rebuilding the local spline
+from scratch after every push
+
+EDGE CASES:
+- sliding windows of several sizes
+- out-of-order points return ErrUnordered
+- non-finite points return ErrNotFinite
+*/
+func TestStreamingSpline(t *testing.T) {
+	xs := make([]float64, 60)
+	ys := make([]float64, 60)
+	for i := range xs {
+		xs[i] = float64(i) + 0.3*math.Sin(float64(i))
+		ys[i] = math.Sin(xs[i]/3) + 0.2*math.Cos(3*xs[i])
+	}
+	rebuild := []func(*BiVariateData) *CubicSplineInterpolation{MonotoneSpline, AkimaSpline, ModifiedAkimaSpline, SteffenSpline}
+	methods := []StreamMethod{StreamMonotone, StreamAkima, StreamModifiedAkima, StreamSteffen}
+
+	for k, method := range methods {
+		for _, window := range []int{0, 5, 12} {
+			s := StreamingSpline(method, window)
+			for n := range xs {
+				if err := s.Push(xs[n], ys[n]); err != nil {
+					t.Fatal(err)
+				}
+				if n == 0 {
+					continue
+				}
+				lo := 0
+				if window > 0 && n+1 > window {
+					lo = n + 1 - window
+				}
+				if s.Len() != n+1-lo {
+					t.Error("Streaming spline holds the wrong number of points. Got:", s.Len(), "Expected:", n+1-lo)
+				}
+				want := rebuild[k](MakeBiVariateData(append([]float64{}, xs[lo:n+1]...), append([]float64{}, ys[lo:n+1]...)))
+				for _, x := range Arange(xs[lo], xs[n], 7) {
+					if math.Abs(s.F(x)-want.F(x)) > 1E-12 || math.Abs(s.DF(x)-want.DF(x)) > 1E-12 {
+						t.Error("Method", method, "window", window, "disagrees with a rebuild at", x, "Got:", s.F(x), "Expected:", want.F(x))
+					}
+				}
+			}
+		}
+	}
+
+	s := StreamingSpline(StreamMonotone, 0)
+	s.Push(1, 1)
+	if err := s.Push(1, 2); err != ErrUnordered {
+		t.Error("Expected ErrUnordered for a repeated x-value. Got:", err)
+	}
+	if err := s.Push(0, 2); err != ErrUnordered {
+		t.Error("Expected ErrUnordered for a decreasing x-value. Got:", err)
+	}
+	if err := s.Push(2, math.NaN()); err != ErrNotFinite {
+		t.Error("Expected ErrNotFinite for NaN. Got:", err)
+	}
+}
+
+/*
+Test BiVariateData.Append
+
+EDGE CASES:
+- interpolations sharing the data see the new point
+- an out-of-order point clears the sorted flag
+*/
+func TestAppend(t *testing.T) {
+	d := MakeBiVariateData([]float64{0, 1}, []float64{0, 1})
+	l := LinearInterp(d)
+	d.Append(2, 4)
+	if l.F(1.5) != 2.5 {
+		t.Error("Linear interpolation after Append is wrong. Got:", l.F(1.5), "Expected:", 2.5)
+	}
+	d.Append(-1, 1)
+	if d.isSorted {
+		t.Error("Data appended out of order should not be marked sorted.")
+	}
+}
@@ -0,0 +1,296 @@
+package vec
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+Reading and writing columns of numbers as CSV, TSV or
+whitespace-separated text
+
+Files are read one record at a time and only the selected
+columns are kept, so large files are never held in memory
+twice. Numbers are parsed and written with strconv, which
+always uses '.' as the decimal point whatever the locale.
+*/
+
+//MissingPolicy - what to do with records that have a missing value
+type MissingPolicy int
+
+const (
+	//MissingSkip - drop the whole record
+	MissingSkip MissingPolicy = iota
+	//MissingNaN - store NaN
+	MissingNaN
+	//MissingError - stop with an error
+	MissingError
+)
+
+/*
+TableOptions - format of a table of numbers
+
+'Comma' separates fields (',' for CSV, '\t' for TSV);
+zero splits on runs of whitespace. Lines beginning with
+'Comment' (if not zero) are ignored; when splitting on
+whitespace, a 'Comment' anywhere on a line also ends it,
+so "1 2 # note" reads as "1 2". If 'Header' is set the
+first record holds column names. 'Cols' selects columns
+(0-based); nil selects every column of the first record.
+
+A field is missing if it is empty, absent from a short
+record, or one of NA, N/A, NaN or null (any case). Records
+may be ragged: fields beyond the selected columns are
+ignored.
+*/
+type TableOptions struct {
+	Comma   rune
+	Comment rune
+	Header  bool
+	Cols    []int
+	Missing MissingPolicy
+}
+
+/*
+Reads the columns 'opts.Cols' of a table from 'r'
+
+Returns the columns, in the order of 'opts.Cols', and the
+names of the selected columns if 'opts.Header' is set.
+Unparseable numbers (and missing values under MissingError)
+are reported with their line number and wrap ErrFormat.
+*/
+func ReadColumns(r io.Reader, opts TableOptions) ([][]float64, []string, error) {
+	next := tableRecords(r, opts)
+	cols := opts.Cols
+	var out [][]float64
+	var names []string
+	if cols != nil {
+		out = make([][]float64, len(cols))
+	}
+	for first := true; ; first = false {
+		rec, line, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if first {
+			if cols == nil {
+				cols = make([]int, len(rec))
+				for i := range cols {
+					cols[i] = i
+				}
+			}
+			if out == nil {
+				out = make([][]float64, len(cols))
+			}
+			if opts.Header {
+				names = make([]string, len(cols))
+				for i, c := range cols {
+					if c < len(rec) {
+						names[i] = strings.TrimSpace(rec[c])
+					}
+				}
+				continue
+			}
+		}
+
+		row, err := parseRecord(rec, cols, line, opts.Missing)
+		if err != nil {
+			return nil, nil, err
+		}
+		if row == nil {
+			continue
+		}
+		for i := range out {
+			out[i] = append(out[i], row[i])
+		}
+	}
+	for i := range out {
+		if out[i] == nil {
+			out[i] = []float64{}
+		}
+	}
+	return out, names, nil
+}
+
+/*
+Reads a BiVariateData from a table, sorted by x-value
+
+'opts.Cols' selects the x and y columns (default {0, 1});
+a third column, if given, is read into Errs. Records whose
+x-value is missing are skipped even under MissingNaN, since
+a NaN x-value has no place in the sorted data.
+*/
+func ReadBiVariateData(r io.Reader, opts TableOptions) (*BiVariateData, error) {
+	if opts.Cols == nil {
+		opts.Cols = []int{0, 1}
+	}
+	if len(opts.Cols) != 2 && len(opts.Cols) != 3 {
+		return nil, ErrLength
+	}
+	cols, _, err := ReadColumns(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	//drop records without an x-value, in place
+	n := 0
+	for i, x := range cols[0] {
+		if math.IsNaN(x) {
+			continue
+		}
+		for _, c := range cols {
+			c[n] = c[i]
+		}
+		n++
+	}
+	d := &BiVariateData{Xs: cols[0][:n], Ys: cols[1][:n]}
+	if len(cols) == 3 {
+		d.Errs = cols[2][:n]
+	}
+	d.Sort()
+	return d, nil
+}
+
+/*
+Writes 'cols' as the columns of a table to 'w', preceded by
+a header of 'names' if it is not nil
+
+Numbers are written in the shortest form that reads back
+exactly. Whitespace tables (opts.Comma == 0) are separated
+by tabs. Returns ErrLength if the columns (and names) differ
+in length.
+*/
+func WriteColumns(w io.Writer, opts TableOptions, names []string, cols ...[]float64) error {
+	for _, c := range cols {
+		if len(c) != len(cols[0]) {
+			return ErrLength
+		}
+	}
+	if names != nil && len(names) != len(cols) {
+		return ErrLength
+	}
+	comma := opts.Comma
+	if comma == 0 {
+		comma = '\t'
+	}
+
+	out := csv.NewWriter(w)
+	out.Comma = comma
+	if names != nil {
+		if err := out.Write(names); err != nil {
+			return err
+		}
+	}
+	if len(cols) > 0 {
+		rec := make([]string, len(cols))
+		for i := range cols[0] {
+			for j, c := range cols {
+				rec[j] = strconv.FormatFloat(c[i], 'g', -1, 64)
+			}
+			if err := out.Write(rec); err != nil {
+				return err
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+/*
+Writes 'b' as a table of x, y (and, if present, error)
+columns to 'w', with a header if 'opts.Header' is set
+*/
+func WriteBiVariateData(w io.Writer, b *BiVariateData, opts TableOptions) error {
+	cols := [][]float64{b.Xs, b.Ys}
+	names := []string{"x", "y"}
+	if b.Errs != nil {
+		cols = append(cols, b.Errs)
+		names = append(names, "err")
+	}
+	if !opts.Header {
+		names = nil
+	}
+	return WriteColumns(w, opts, names, cols...)
+}
+
+//returns a function yielding each record of the table and its line number
+func tableRecords(r io.Reader, opts TableOptions) func() ([]string, int, error) {
+	if opts.Comma != 0 {
+		cr := csv.NewReader(r)
+		cr.Comma = opts.Comma
+		cr.Comment = opts.Comment
+		cr.FieldsPerRecord = -1
+		cr.LazyQuotes = true
+		cr.ReuseRecord = true
+		return func() ([]string, int, error) {
+			rec, err := cr.Read()
+			if err != nil {
+				return nil, 0, err
+			}
+			line, _ := cr.FieldPos(0)
+			return rec, line, nil
+		}
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	return func() ([]string, int, error) {
+		for sc.Scan() {
+			line++
+			text := sc.Text()
+			if opts.Comment != 0 {
+				if i := strings.IndexRune(text, opts.Comment); i >= 0 {
+					text = text[:i]
+				}
+			}
+			fields := strings.Fields(text)
+			if len(fields) == 0 {
+				continue
+			}
+			return fields, line, nil
+		}
+		if err := sc.Err(); err != nil {
+			return nil, 0, err
+		}
+		return nil, 0, io.EOF
+	}
+}
+
+/*
+Parses the columns 'cols' of a record; returns a nil row
+if the record is skipped for a missing value
+*/
+func parseRecord(rec []string, cols []int, line int, missing MissingPolicy) ([]float64, error) {
+	row := make([]float64, len(cols))
+	for i, c := range cols {
+		f := ""
+		if c < len(rec) {
+			f = strings.TrimSpace(rec[c])
+		}
+		switch strings.ToLower(f) {
+		case "", "na", "n/a", "nan", "null":
+			switch missing {
+			case MissingSkip:
+				return nil, nil
+			case MissingError:
+				return nil, fmt.Errorf("vec: line %d, column %d: missing value: %w", line, c, ErrFormat)
+			}
+			row[i] = math.NaN()
+			continue
+		}
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, fmt.Errorf("vec: line %d, column %d: %q is not a number: %w", line, c, f, ErrFormat)
+		}
+		row[i] = v
+	}
+	return row, nil
+}
@@ -0,0 +1,101 @@
+package vec
+
+import "testing"
+import "math"
+import "bytes"
+import "errors"
+import "strings"
+
+/*
+Test reading delimited tables
+
+- quoted fields, comments, headers and column selection
+- whitespace-separated columns
+
+EDGE CASES:
+- rows with missing values are dropped or read as NaN
+- rows with a missing x-value are always dropped from BiVariateData
+- comments that start mid-line in whitespace tables
+- ragged rows are read, short ones as missing values
+- unparsable fields return ErrFormat with a line number
+*/
+func TestReadColumns(t *testing.T) {
+	csvText := "# a comment\ntime,\"value, raw\",err\n3,9.5,0.1\n1,1e-3,0.2\n2,NA,0.3\n4,-2,\n"
+	d, err := ReadBiVariateData(strings.NewReader(csvText), TableOptions{Comma: ',', Comment: '#', Header: true, Cols: []int{0, 1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Xs) != 2 || d.Xs[0] != 1 || d.Ys[0] != 1E-3 || d.Errs[1] != 0.1 {
+		t.Error("ReadBiVariateData is wrong. Got:", d.Xs, d.Ys, d.Errs, "Expected: [1 3] [0.001 9.5] [0.2 0.1]")
+	}
+
+	d, err = ReadBiVariateData(strings.NewReader("2,5\nNA,6\n1,NA\n"), TableOptions{Comma: ',', Missing: MissingNaN})
+	if err != nil || len(d.Xs) != 2 || d.Xs[0] != 1 || !math.IsNaN(d.Ys[0]) || d.Ys[1] != 5 {
+		t.Error("ReadBiVariateData kept a record without an x-value. Got:", d.Xs, d.Ys, err)
+	}
+
+	ws := "  x   y  z\n# skipped\n  #indented\n1.5\t2  3#trailing\n\n-4 5e2 NaN\n"
+	cols, names, err := ReadColumns(strings.NewReader(ws), TableOptions{Comment: '#', Header: true, Cols: []int{2, 0}, Missing: MissingNaN})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names[0] != "z" || names[1] != "x" || cols[0][0] != 3 || !math.IsNaN(cols[0][1]) || cols[1][1] != -4 {
+		t.Error("ReadColumns is wrong. Got:", names, cols)
+	}
+
+	ragged := "1,2\n3\n4,5,6\n"
+	cols, _, err = ReadColumns(strings.NewReader(ragged), TableOptions{Comma: ','})
+	if err != nil || len(cols[0]) != 2 || cols[0][1] != 4 || cols[1][1] != 5 {
+		t.Error("ReadColumns should skip the short row. Got:", cols, err)
+	}
+	cols, _, err = ReadColumns(strings.NewReader(ragged), TableOptions{Comma: ',', Missing: MissingNaN})
+	if err != nil || len(cols[0]) != 3 || cols[0][1] != 3 || !math.IsNaN(cols[1][1]) {
+		t.Error("ReadColumns should read the short row as NaN. Got:", cols, err)
+	}
+	_, _, err = ReadColumns(strings.NewReader(ragged), TableOptions{Comma: ',', Missing: MissingError})
+	if !errors.Is(err, ErrFormat) || !strings.Contains(err.Error(), "line 2") {
+		t.Error("Expected ErrFormat for the short row on line 2. Got:", err)
+	}
+
+	_, _, err = ReadColumns(strings.NewReader("1,2\n3,4,5\n6,x\n"), TableOptions{Comma: ','})
+	if !errors.Is(err, ErrFormat) || !strings.Contains(err.Error(), "line 3") {
+		t.Error("Expected ErrFormat on line 3. Got:", err)
+	}
+	_, _, err = ReadColumns(strings.NewReader("1\t2\n3\t\n"), TableOptions{Comma: '\t', Missing: MissingError})
+	if !errors.Is(err, ErrFormat) {
+		t.Error("Expected ErrFormat for a missing value. Got:", err)
+	}
+}
+
+/*
+Test writing tables
+
+- data survives a write/read round trip with each delimiter
+
+EDGE CASES:
+- ragged columns return ErrLength
+*/
+func TestWriteColumns(t *testing.T) {
+	d := MakeBiVariateData([]float64{0.1, 1.0 / 3, 2e-300}, []float64{math.Pi, -1, math.Inf(1)})
+	d.Errs = []float64{1, 2, 3}
+	for _, comma := range []rune{',', '\t', 0} {
+		var buf bytes.Buffer
+		opts := TableOptions{Comma: comma, Header: true, Cols: []int{0, 1, 2}}
+		if err := WriteBiVariateData(&buf, d, opts); err != nil {
+			t.Fatal(err)
+		}
+		e, err := ReadBiVariateData(&buf, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range d.Xs {
+			if e.Xs[i] != d.Xs[i] || e.Ys[i] != d.Ys[i] || e.Errs[i] != d.Errs[i] {
+				t.Error("Table round trip changed the data. Got:", e.Xs, e.Ys, e.Errs)
+				break
+			}
+		}
+	}
+	if WriteColumns(&bytes.Buffer{}, TableOptions{}, nil, []float64{1}, []float64{}) != ErrLength {
+		t.Error("Expected ErrLength for ragged columns.")
+	}
+}
@@ -0,0 +1,335 @@
+package vec
+
+import "math"
+
+/*
+Propagation of y-uncertainties through cubic splines
+
+Every value the spline returns (F, DF, Integral) is a
+linear combination of its knot values and coefficients;
+the standard error of that combination is propagated back
+to the y-values of the data, and contributions from
+independent points add in quadrature.
+
+Splines that are linear in their y-values and keep their
+linear system (CubicSpline, CubicSplineEnds, SmoothingSpline)
+propagate exactly: each evaluation solves the transposed
+system for the sensitivities to every y-value, in O(N) time
+and memory. Other splines (the local splines) are rebuilt
+with each y-value moved by its standard error, which is a
+finite-difference linearization; each point then changes
+only a few neighbouring knots, which is all that is kept.
+*/
+
+//UncertainInterpolation type def
+type UncertainInterpolation struct {
+	spline *CubicSplineInterpolation
+	errs   []float64
+	sens   []sensitivity //for splines without an adjoint
+	reach  int           //farthest knot from a point that the point changes
+}
+
+/*
+change in the knot values and coefficients of knots
+lo, lo+1, ... when one y-value moves by its error
+(zero at the knots outside that range)
+*/
+type sensitivity struct {
+	lo      int
+	dys     []float64
+	dcoeffs []float64
+}
+
+/*
+Builds the spline 'build(d)' along with what is needed to
+propagate the standard errors 'd.Errs'
+
+e.g. u, err := UncertainSpline(bvd, CubicSpline)
+
+For periodic splines the last point repeats the first and
+its error is not used. Returns ErrLength if 'd' has fewer
+than two points or 'd.Errs' is not the length of the data,
+ErrNotFinite if an error is NaN, infinite or negative, and
+ErrParam if 'build' is nil or does not return a spline on
+the same x-values.
+*/
+func UncertainSpline(d *BiVariateData, build func(*BiVariateData) *CubicSplineInterpolation) (*UncertainInterpolation, error) {
+	N := len(d.Xs)
+	if N < 2 || len(d.Errs) != N {
+		return nil, ErrLength
+	}
+	for _, e := range d.Errs {
+		if !(e >= 0) || math.IsInf(e, 1) {
+			return nil, ErrNotFinite
+		}
+	}
+	if build == nil {
+		return nil, ErrParam
+	}
+	d.Sort()
+	s := build(d)
+	if s == nil || len(s.data.Xs) != N || len(s.coeffs) != N {
+		return nil, ErrParam
+	}
+
+	u := &UncertainInterpolation{spline: s, errs: append([]float64{}, d.Errs...)}
+	if s.adj != nil {
+		return u, nil
+	}
+
+	u.sens = make([]sensitivity, N)
+	ys := make([]float64, N)
+	dys := make([]float64, N)
+	dcoeffs := make([]float64, N)
+	for j := range u.sens {
+		if d.Errs[j] == 0 {
+			continue
+		}
+		copy(ys, d.Ys)
+		ys[j] += d.Errs[j]
+		p := build(&BiVariateData{Xs: d.Xs, Ys: ys, isSorted: true})
+		if p == nil || len(p.coeffs) != N {
+			return nil, ErrParam
+		}
+
+		//keep the knots from the first to the last one that changed
+		lo, hi := N, -1
+		for k := range dys {
+			dys[k] = p.data.Ys[k] - s.data.Ys[k]
+			dcoeffs[k] = p.coeffs[k] - s.coeffs[k]
+			if dys[k] != 0 || dcoeffs[k] != 0 {
+				if lo == N {
+					lo = k
+				}
+				hi = k
+			}
+		}
+		if hi < lo {
+			continue
+		}
+		u.sens[j] = sensitivity{
+			lo:      lo,
+			dys:     append([]float64{}, dys[lo:hi+1]...),
+			dcoeffs: append([]float64{}, dcoeffs[lo:hi+1]...),
+		}
+		if j-lo > u.reach {
+			u.reach = j - lo
+		}
+		if hi-j > u.reach {
+			u.reach = hi - j
+		}
+	}
+	return u, nil
+}
+
+//Returns the underlying spline
+func (u *UncertainInterpolation) Spline() *CubicSplineInterpolation {
+	return u.spline
+}
+
+/*
+Weights on the knot values and coefficients of a spline
+that give one of its outputs; knots lo...hi may be non-zero
+*/
+type knotWeights struct {
+	s      *CubicSplineInterpolation
+	wy, wD []float64
+	lo, hi int
+}
+
+func newKnotWeights(s *CubicSplineInterpolation) *knotWeights {
+	N := len(s.coeffs)
+	return &knotWeights{s: s, wy: make([]float64, N), wD: make([]float64, N), lo: N, hi: -1}
+}
+
+/*
+Adds the weights 'p' on the cubic a + b*t + c*t^2 + d*t^3
+of segment 'i' (see segment())
+*/
+func (w *knotWeights) segment(i int, p [4]float64) {
+	xs := w.s.data.Xs
+	pa, pb, pc, pd := p[0], p[1], p[2], p[3]
+
+	//transpose of hermiteCoeffs
+	w.wy[i] += pa - 3*pc + 2*pd
+	w.wy[i+1] += 3*pc - 2*pd
+	w.wD[i] += pb - 2*pc + pd
+	D1 := pd - pc
+	if i+2 < len(xs) {
+		D1 *= (xs[i+1] - xs[i]) / (xs[i+2] - xs[i+1])
+	}
+	w.wD[i+1] += D1
+
+	if i < w.lo {
+		w.lo = i
+	}
+	if i+1 > w.hi {
+		w.hi = i + 1
+	}
+}
+
+//Adds 'scale' times the 'deriv'-th derivative (0 or 1) at 'x'
+func (w *knotWeights) point(x float64, deriv int, scale float64) {
+	i, _ := w.s.data.findXBounds(x)
+	h := w.s.data.Xs[i+1] - w.s.data.Xs[i]
+	t := (x - w.s.data.Xs[i]) / h
+	switch deriv {
+	case 0:
+		w.segment(i, [4]float64{scale, scale * t, scale * t * t, scale * t * t * t})
+	case 1:
+		f := scale / h
+		w.segment(i, [4]float64{0, f, 2 * f * t, 3 * f * t * t})
+	}
+}
+
+//Adds the integral from 'a' to 'b' (a <= b), extending the end segments
+func (w *knotWeights) integral(a float64, b float64) {
+	xs := w.s.data.Xs
+	ia, _ := w.s.data.findXBounds(a)
+	ib, _ := w.s.data.findXBounds(b)
+	for i := ia; i <= ib; i++ {
+		h := xs[i+1] - xs[i]
+		t1, t2 := 0.0, 1.0
+		if i == ia {
+			t1 = (a - xs[i]) / h
+		}
+		if i == ib {
+			t2 = (b - xs[i]) / h
+		}
+		pow := func(k float64) float64 {
+			return h * (math.Pow(t2, k) - math.Pow(t1, k)) / k
+		}
+		w.segment(i, [4]float64{pow(1), pow(2), pow(3), pow(4)})
+	}
+}
+
+//standard error of the spline output with weights 'w'
+func (u *UncertainInterpolation) stdErr(w *knotWeights) float64 {
+	variance := 0.0
+	if u.spline.adj != nil {
+		for j, s := range u.spline.adj(w.wy, w.wD) {
+			e := s * u.errs[j]
+			variance += e * e
+		}
+		return math.Sqrt(variance)
+	}
+
+	//only points within 'reach' of the weighted knots change them
+	lo, hi := w.lo-u.reach, w.hi+u.reach
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > len(u.sens)-1 {
+		hi = len(u.sens) - 1
+	}
+	for j := lo; j <= hi; j++ {
+		sn := u.sens[j]
+		e := 0.0
+		for k := range sn.dys {
+			e += w.wy[sn.lo+k]*sn.dys[k] + w.wD[sn.lo+k]*sn.dcoeffs[k]
+		}
+		variance += e * e
+	}
+	return math.Sqrt(variance)
+}
+
+/*
+standard error of the 'deriv'-th derivative at 'x',
+following the spline's extrapolation policy
+*/
+func (u *UncertainInterpolation) pointErr(x float64, deriv int) float64 {
+	if notRat(x) {
+		return math.NaN()
+	}
+	w := newKnotWeights(u.spline)
+	lo, hi := u.spline.Domain()
+	ext := u.spline.ext
+	if ext == ExtrapolatePoly || (x >= lo && x <= hi) {
+		w.point(x, deriv, 1.0)
+		return u.stdErr(w)
+	}
+	edge := lo
+	if x > hi {
+		edge = hi
+	}
+
+	switch ext {
+	case ExtrapolateError:
+		panic(ErrDomain)
+	case ExtrapolateClamp:
+		if deriv == 0 {
+			w.point(edge, 0, 1.0)
+		}
+	case ExtrapolateLinear:
+		if deriv == 0 {
+			w.point(edge, 0, 1.0)
+			w.point(edge, 1, x-edge)
+		} else {
+			w.point(edge, 1, 1.0)
+		}
+	default:
+		return math.NaN()
+	}
+	return u.stdErr(w)
+}
+
+/*
+Returns the interpolated value at 'x' and its standard error
+
+Outside the data, the error follows the spline's
+Extrapolation: it is that of the polynomial extension of
+the end segments, of the clamped value or tangent line at
+the edge, or NaN.
+*/
+func (u *UncertainInterpolation) F(x float64) (float64, float64) {
+	return u.spline.F(x), u.pointErr(x, 0)
+}
+
+//Returns the first derivative at 'x' and its standard error
+func (u *UncertainInterpolation) DF(x float64) (float64, float64) {
+	return u.spline.DF(x), u.pointErr(x, 1)
+}
+
+//Returns the integral from 'a' to 'b' and its standard error
+func (u *UncertainInterpolation) Integral(a float64, b float64) (float64, float64) {
+	v := u.spline.Integral(a, b)
+	if notRat(a) || notRat(b) {
+		return v, math.NaN()
+	}
+	if a > b {
+		a, b = b, a
+	}
+
+	w := newKnotWeights(u.spline)
+	lo, hi := u.spline.Domain()
+	ext := u.spline.ext
+	if ext == ExtrapolatePoly || (a >= lo && b <= hi) {
+		w.integral(a, b)
+		return v, u.stdErr(w)
+	}
+
+	//as in extrapIntegral
+	tail := func(edge float64, x1 float64, x2 float64) {
+		w.point(edge, 0, x2-x1)
+		if ext == ExtrapolateLinear {
+			w.point(edge, 1, ((x2-edge)*(x2-edge)-(x1-edge)*(x1-edge))/2.0)
+		}
+	}
+	switch ext {
+	case ExtrapolateError:
+		panic(ErrDomain)
+	case ExtrapolateClamp, ExtrapolateLinear:
+	default:
+		return v, math.NaN()
+	}
+	if a < lo {
+		tail(lo, a, math.Min(b, lo))
+	}
+	if ca, cb := math.Max(a, lo), math.Min(b, hi); ca < cb {
+		w.integral(ca, cb)
+	}
+	if b > hi {
+		tail(hi, math.Max(a, hi), b)
+	}
+	return v, u.stdErr(w)
+}
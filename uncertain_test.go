@@ -0,0 +1,145 @@
+package vec
+
+import "testing"
+import "math"
+
+//brute-force linear propagation through every point
+func bruteErr(d *BiVariateData, build func(*BiVariateData) *CubicSplineInterpolation, f func(*CubicSplineInterpolation) float64) float64 {
+	base := f(build(&BiVariateData{Xs: d.Xs, Ys: d.Ys, isSorted: true}))
+	variance := 0.0
+	for j := range d.Ys {
+		ys := append([]float64{}, d.Ys...)
+		ys[j] += d.Errs[j]
+		e := f(build(&BiVariateData{Xs: d.Xs, Ys: ys, isSorted: true})) - base
+		variance += e * e
+	}
+	return math.Sqrt(variance)
+}
+
+/*
+Test propagation of standard errors through splines
+
+- F, DF and Integral errors match brute-force propagation
+for a global, a local, a smoothing and a periodic spline
+(the last two depend on every knot, and the periodic one
+wraps around the ends)
+- at a knot, an interpolating spline has that point's error
+- outside the data, the errors follow each extrapolation policy
+- large data sets build in O(N)
+
+EDGE CASES:
+- data without errors returns ErrLength
+- NaN and negative errors return ErrNotFinite
+*/
+func TestUncertainSpline(t *testing.T) {
+	N := 60
+	xs := make([]float64, N)
+	ys := make([]float64, N)
+	errs := make([]float64, N)
+	for i := range xs {
+		xs[i] = float64(i) + 0.4*math.Sin(float64(3*i))
+		ys[i] = math.Cos(xs[i] / 5)
+		errs[i] = 0.01 * (1 + float64(i%3))
+	}
+	d := &BiVariateData{Xs: xs, Ys: ys, Errs: errs}
+	d.Sort()
+
+	smooth := func(b *BiVariateData) *CubicSplineInterpolation {
+		s, _ := SmoothingSpline(b, nil, 1E6)
+		return s
+	}
+	//the last point repeats the first, so only the first one counts
+	periodic := func(b *BiVariateData) *CubicSplineInterpolation {
+		ys := append([]float64{}, b.Ys...)
+		ys[len(ys)-1] = ys[0]
+		s, _ := CubicSplineEnds(&BiVariateData{Xs: b.Xs, Ys: ys, isSorted: true}, SplineEnds{Cond: Periodic})
+		return s
+	}
+
+	names := []string{"CubicSpline", "MonotoneSpline", "SmoothingSpline", "Periodic"}
+	for k, build := range []func(*BiVariateData) *CubicSplineInterpolation{CubicSpline, MonotoneSpline, smooth, periodic} {
+		u, err := UncertainSpline(d, build)
+		if err != nil {
+			t.Fatal(names[k], "failed. Got:", err)
+		}
+		for _, x := range []float64{0.2, 7.5, 30.1, 40.5, 58.7} {
+			v, e := u.F(x)
+			want := bruteErr(d, build, func(s *CubicSplineInterpolation) float64 { return s.F(x) })
+			if v != u.Spline().F(x) || math.Abs(e-want) > 1E-9 {
+				t.Error(names[k], "standard error is wrong at", x, "Got:", e, "Expected:", want)
+			}
+			_, e = u.DF(x)
+			want = bruteErr(d, build, func(s *CubicSplineInterpolation) float64 { return s.DF(x) })
+			if math.Abs(e-want) > 1E-9 {
+				t.Error(names[k], "derivative standard error is wrong at", x, "Got:", e, "Expected:", want)
+			}
+		}
+		for _, ab := range [][2]float64{{0, 59}, {10.3, 12.9}, {40, 5}} {
+			_, e := u.Integral(ab[0], ab[1])
+			want := bruteErr(d, build, func(s *CubicSplineInterpolation) float64 { return s.Integral(ab[0], ab[1]) })
+			if math.Abs(e-want) > 1E-9 {
+				t.Error(names[k], "integral standard error is wrong on", ab, "Got:", e, "Expected:", want)
+			}
+		}
+	}
+
+	u, _ := UncertainSpline(d, CubicSpline)
+	if _, e := u.F(d.Xs[20]); math.Abs(e-d.Errs[20]) > 1E-12 {
+		t.Error("Standard error at a knot is wrong. Got:", e, "Expected:", d.Errs[20])
+	}
+
+	for _, ext := range []Extrapolation{ExtrapolateClamp, ExtrapolateLinear} {
+		for k, base := range []func(*BiVariateData) *CubicSplineInterpolation{CubicSpline, MonotoneSpline} {
+			build := func(b *BiVariateData) *CubicSplineInterpolation {
+				return base(b).Extrapolate(ext)
+			}
+			u, _ := UncertainSpline(d, build)
+			for _, x := range []float64{-3, 62.5} {
+				_, e := u.F(x)
+				want := bruteErr(d, build, func(s *CubicSplineInterpolation) float64 { return s.F(x) })
+				if math.Abs(e-want) > 1E-9 {
+					t.Error(names[k], "standard error with policy", ext, "is wrong at", x, "Got:", e, "Expected:", want)
+				}
+				_, e = u.DF(x)
+				want = bruteErr(d, build, func(s *CubicSplineInterpolation) float64 { return s.DF(x) })
+				if math.Abs(e-want) > 1E-9 {
+					t.Error(names[k], "derivative standard error with policy", ext, "is wrong at", x, "Got:", e, "Expected:", want)
+				}
+			}
+			_, e := u.Integral(-4, 63)
+			want := bruteErr(d, build, func(s *CubicSplineInterpolation) float64 { return s.Integral(-4, 63) })
+			if math.Abs(e-want) > 1E-9 {
+				t.Error(names[k], "integral standard error with policy", ext, "is wrong. Got:", e, "Expected:", want)
+			}
+		}
+	}
+	u, _ = UncertainSpline(d, func(b *BiVariateData) *CubicSplineInterpolation {
+		return CubicSpline(b).Extrapolate(ExtrapolateNaN)
+	})
+	if _, e := u.F(-3); !math.IsNaN(e) {
+		t.Error("Expected a NaN standard error outside the data. Got:", e)
+	}
+
+	M := 100000
+	big := &BiVariateData{Xs: Arange(0, 1, M), Ys: Arange(0, 1, M), Errs: make([]float64, M)}
+	for i := range big.Errs {
+		big.Errs[i] = 0.5
+	}
+	u, _ = UncertainSpline(big, CubicSpline)
+	if _, e := u.F(big.Xs[M/2]); math.Abs(e-0.5) > 1E-12 {
+		t.Error("Standard error at a knot of a large data set is wrong. Got:", e, "Expected:", 0.5)
+	}
+
+	if _, err := UncertainSpline(MakeBiVariateData(xs, ys), CubicSpline); err != ErrLength {
+		t.Error("Expected ErrLength for data without errors. Got:", err)
+	}
+	bad := &BiVariateData{Xs: xs, Ys: ys, Errs: append([]float64{}, errs...)}
+	bad.Errs[3] = math.NaN()
+	if _, err := UncertainSpline(bad, CubicSpline); err != ErrNotFinite {
+		t.Error("Expected ErrNotFinite for a NaN error. Got:", err)
+	}
+	bad.Errs[3] = -0.1
+	if _, err := UncertainSpline(bad, CubicSpline); err != ErrNotFinite {
+		t.Error("Expected ErrNotFinite for a negative error. Got:", err)
+	}
+}